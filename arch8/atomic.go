@@ -0,0 +1,104 @@
+package arch8
+
+// reservations keeps the word reserved by load-linked on each core. Any
+// write to a reserved word by a core breaks the reservation, so that the
+// following store-conditional fails. Writes by devices with DMA do not
+// break reservations.
+type reservations struct {
+	addrs [32]uint32
+	valid uint32 // bit i is set when core i holds a reservation
+}
+
+func (r *reservations) reserve(core byte, pa uint32) {
+	r.addrs[core] = pa &^ 0x3
+	r.valid |= 1 << core
+}
+
+// drop breaks the reservation of a core.
+func (r *reservations) drop(core byte) {
+	r.valid &^= 1 << core
+}
+
+// take breaks the reservation of a core, and returns true if the core
+// was holding the reservation of the word.
+func (r *reservations) take(core byte, pa uint32) bool {
+	ok := r.valid&(1<<core) != 0 && r.addrs[core] == pa&^0x3
+	r.drop(core)
+	return ok
+}
+
+// written breaks all the reservations of the word at pa.
+func (r *reservations) written(pa uint32) {
+	if r.valid == 0 {
+		return
+	}
+	pa &^= 0x3
+	for i, a := range r.addrs {
+		if a == pa {
+			r.drop(byte(i))
+		}
+	}
+}
+
+// loadLinked reads a word and reserves it for storeCond.
+func (c *cpu) loadLinked(addr uint32) (uint32, *Excep) {
+	v, e := c.readWord(addr)
+	if e != nil {
+		return 0, e
+	}
+	if c.virtMem.resv != nil {
+		pa, e := c.virtMem.transRead(addr, c.ring)
+		if e != nil {
+			return 0, e
+		}
+		c.virtMem.resv.reserve(c.index, pa)
+	}
+	return v, nil
+}
+
+// storeCond writes a word only if the core still holds the reservation
+// of the word made by loadLinked, and returns if the word is written.
+func (c *cpu) storeCond(addr, v uint32) (bool, *Excep) {
+	if c.virtMem.resv == nil {
+		return false, nil
+	}
+	pa, e := c.virtMem.transWrite(addr, c.ring)
+	if e != nil {
+		return false, e
+	}
+	if !c.virtMem.resv.take(c.index, pa) {
+		return false, nil
+	}
+	if e := c.writeWord(addr, v); e != nil {
+		return false, e
+	}
+	return true, nil
+}
+
+// compareAndSwap writes v into the word at addr if the word equals to
+// old, and returns the value of the word before.
+func (c *cpu) compareAndSwap(addr, old, v uint32) (uint32, *Excep) {
+	cur, e := c.readWord(addr)
+	if e != nil {
+		return 0, e
+	}
+	if cur == old {
+		if e := c.writeWord(addr, v); e != nil {
+			return 0, e
+		}
+	}
+	return cur, nil
+}
+
+// fetchAdd adds delta to the word at addr, and returns the value of the
+// word before.
+func (c *cpu) fetchAdd(addr, delta uint32) (uint32, *Excep) {
+	cur, e := c.readWord(addr)
+	if e != nil {
+		return 0, e
+	}
+	if e := c.writeWord(addr, cur+delta); e != nil {
+		return 0, e
+	}
+	return cur, nil
+}
@@ -0,0 +1,47 @@
+package arch8
+
+import (
+	"testing"
+)
+
+func TestLoadLinked(t *testing.T) {
+	m := NewMachine(PageSize*64, 2)
+	c0 := m.cores.cores[0]
+	c1 := m.cores.cores[1]
+
+	const addr = 0x9000
+	if _, e := c0.loadLinked(addr); e != nil {
+		t.Fatal(e)
+	}
+	if ok, e := c0.storeCond(addr, 1); e != nil || !ok {
+		t.Fatal("store-conditional failed without contention")
+	}
+	if ok, _ := c0.storeCond(addr, 2); ok {
+		t.Fatal("store-conditional succeeded without a reservation")
+	}
+
+	c0.loadLinked(addr)
+	c1.writeByte(addr+2, 7)
+	if ok, _ := c0.storeCond(addr, 3); ok {
+		t.Fatal("reservation not broken by another core")
+	}
+
+	c0.loadLinked(addr)
+	c1.writeWord(addr+4, 7)
+	if ok, _ := c0.storeCond(addr, 3); !ok {
+		t.Fatal("reservation broken by a write to another word")
+	}
+	if v, _ := m.ReadWord(addr); v != 3 {
+		t.Fatalf("got %d, want 3", v)
+	}
+
+	if old, _ := c1.compareAndSwap(addr, 3, 5); old != 3 {
+		t.Fatalf("cas got old value %d", old)
+	}
+	if old, _ := c1.fetchAdd(addr, 2); old != 5 {
+		t.Fatalf("xadd got old value %d", old)
+	}
+	if v, _ := m.ReadWord(addr); v != 7 {
+		t.Fatalf("got %d, want 7", v)
+	}
+}
@@ -1,4 +1,6 @@
 package arch8
 
 // AddrBootArg is the address to write the boot argument
-const AddrBootArg = pageBasicIO*PageSize + 8
+const AddrBootArg = pageBasicIO*PageSize + bootArgOffset
+
+const bootArgOffset = 8
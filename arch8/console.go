@@ -20,11 +20,10 @@ type console struct {
 }
 
 // NewConsole creates a new simple console.
-func newConsole(p *page, i intBus) *console {
+func newConsole(p *pageOffset, i intBus) *console {
 	ret := new(console)
 	ret.intBus = i
-	const consoleBase = 0
-	ret.p = &pageOffset{p, consoleBase}
+	ret.p = p
 
 	ret.Core = 0
 	ret.IntIn = 8
@@ -40,6 +39,9 @@ const (
 
 	consoleIn      = 4
 	consoleInValid = 5
+
+	consoleBase = 0
+	consoleSize = 8
 )
 
 func (c *console) interrupt(code byte) {
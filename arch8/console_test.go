@@ -1,3 +1,3 @@
 package arch8
 
-var _ device = new(console)
+var _ Device = new(console)
@@ -0,0 +1,66 @@
+package arch8
+
+// running states of a core
+const (
+	coreRunning = 0
+	coreWaiting = 1 // waits for an interrupt after a wfi instruction
+	coreHalted  = 2 // halted in core halt mode, waits for an ipi
+)
+
+// awake checks if the core runs in this tick. A waiting core wakes up
+// when there is an interrupt pending and not masked, even if interrupts
+// are disabled, and a halted core wakes up when there is an ipi pending,
+// even if it is masked. An ipi that wakes up a halted core but will not
+// be delivered is cleared, so that it does not wake up the next halt.
+func (c *cpu) awake() bool {
+	switch c.state {
+	case coreWaiting:
+		if pending, _ := c.interrupt.next(); !pending {
+			return false
+		}
+	case coreHalted:
+		in := c.interrupt
+		if !in.Pending(IntIPI) {
+			return false
+		}
+		if !in.Enabled() || !in.IntEnabled(IntIPI) {
+			in.Clear(IntIPI)
+		}
+	}
+	c.state = coreRunning
+	return true
+}
+
+// ipi issues an inter-processor interrupt to a core.
+func (c *cpu) ipi(core uint32) *Excep {
+	if c.bus == nil || core >= uint32(c.bus.Ncore()) {
+		return errInvalidInst
+	}
+	c.bus.Interrupt(IntIPI, byte(core))
+	return nil
+}
+
+// halt puts the core into halted state in core halt mode. The core
+// continues with the next instruction when it is woken up.
+func (c *cpu) halt() {
+	c.state = coreHalted
+	c.regs[PC] += 4
+	c.interrupt.Clear(ErrHalt) // the halt is handled
+}
+
+// allHalted checks if all the cores are halted.
+func (c *multiCore) allHalted() bool {
+	for _, core := range c.cores {
+		if core.state != coreHalted {
+			return false
+		}
+	}
+	return true
+}
+
+// SetCoreHalt sets the core halt mode. In this mode, a halt instruction
+// only halts the core that executes it, and another core can wake it up
+// with an ipi. The machine halts when all the cores are halted.
+func (m *Machine) SetCoreHalt(on bool) {
+	m.cores.coreHalt = on
+}
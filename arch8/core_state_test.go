@@ -0,0 +1,41 @@
+package arch8
+
+import (
+	"testing"
+)
+
+func TestCoreHalt(t *testing.T) {
+	m := NewMachine(PageSize*64, 2)
+	m.SetCoreHalt(true)
+
+	addi := func(d uint32) uint32 { return (ADDI << 24) | (d << 21) | 1 }
+	sys := func(op, r uint32) uint32 { return (op << 24) | (r << 21) }
+
+	const pc1 = InitPC + 0x100
+	m.WriteWord(InitPC, sys(WFI, 0))
+	m.WriteWord(InitPC+4, addi(R2))
+	m.WriteWord(InitPC+8, sys(IPI, R1)) // wakes up core 1
+	m.WriteWord(InitPC+12, HALT<<24)
+	m.WriteWord(pc1, sys(IPI, R0)) // wakes up core 0
+	m.WriteWord(pc1+4, HALT<<24)
+	m.WriteWord(pc1+8, addi(R3))
+	m.WriteWord(pc1+12, HALT<<24)
+
+	m.SetReg(0, R1, 1)
+	m.SetReg(1, PC, pc1)
+	m.cores.cores[0].interrupt.EnableInt(IntIPI) // but disabled
+
+	n, e := m.Run(100)
+	if e == nil || e.Code != ErrHalt {
+		t.Fatalf("got %v, want halt", e)
+	}
+	if n > 10 {
+		t.Fatalf("took %d ticks to halt", n)
+	}
+	if m.Reg(0, R2) != 1 || m.Reg(1, R3) != 1 {
+		t.Fatal("cores not woken up")
+	}
+	if m.cores.cores[1].interrupt.Pending(IntIPI) {
+		t.Fatal("masked ipi still pending after waking up")
+	}
+}
@@ -0,0 +1,97 @@
+package arch8
+
+// Opcode spaces that Opcode returns for the instructions that do not
+// have a major opcode of their own.
+const (
+	OpReg   = 0x100 // plus the funct of an integer register instruction
+	OpFloat = 0x200 // plus the funct of a float instruction
+	OpJump  = 0x300 // plus J or JAL
+)
+
+// Opcode returns the opcode of an instruction, which is the major
+// opcode for immediate, branch and system instructions, and is in the
+// OpReg, OpFloat or OpJump spaces for the others.
+func Opcode(in uint32) uint32 {
+	if in>>31 != 0 {
+		return OpJump + (in>>30)&0x3
+	}
+	if major := (in >> 24) & 0xff; major != 0 {
+		return major
+	}
+	if (in>>8)&0x1 != 0 {
+		return OpFloat + in&0xff
+	}
+	return OpReg + in&0xff
+}
+
+// CostModel defines the cycles that an instruction takes. A core that
+// executes an instruction of n cycles stalls for the next n-1 ticks.
+// Cycles of 0 is counted as 1.
+type CostModel struct {
+	Inst     uint32            // cycles of an instruction not in Ops
+	Ops      map[uint32]uint32 // cycles by the opcodes from Opcode
+	Mem      uint32            // extra cycles of a load or a store
+	PageWalk uint32            // extra cycles of a tlb miss
+}
+
+// DefaultCostModel returns a cost model where multiplications,
+// divisions, float instructions and memory accesses take longer.
+func DefaultCostModel() *CostModel {
+	ops := map[uint32]uint32{
+		OpReg + MUL:  3,
+		OpReg + MULU: 3,
+		OpReg + DIV:  20,
+		OpReg + DIVU: 20,
+		OpReg + MOD:  20,
+		OpReg + MODU: 20,
+		OpReg + CAS:  4,
+		OpReg + XADD: 4,
+
+		OpFloat + FMUL:  4,
+		OpFloat + FDIV:  16,
+		OpFloat + FSQRT: 20,
+
+		IRET:    4,
+		SYSCALL: 4,
+	}
+	for _, op := range []uint32{
+		FADD, FSUB, FINT, FEQ, FLT, FLE, FITOF, FABS, FNEG, FTOI,
+	} {
+		ops[OpFloat+op] = 2
+	}
+	return &CostModel{Inst: 1, Ops: ops, Mem: 2, PageWalk: 10}
+}
+
+func isMemOp(op uint32) bool {
+	switch op {
+	case LW, LB, LBU, SW, SB, LL, SC, OpReg + CAS, OpReg + XADD:
+		return true
+	}
+	return false
+}
+
+// cycles returns the cycles of an instruction that walked the page table
+// for walks times.
+func (m *CostModel) cycles(in uint32, walks uint64) uint32 {
+	op := Opcode(in)
+	ret, found := m.Ops[op]
+	if !found {
+		ret = m.Inst
+	}
+	if ret == 0 {
+		ret = 1
+	}
+	if isMemOp(op) {
+		ret += m.Mem
+	}
+	return ret + uint32(walks)*m.PageWalk
+}
+
+// SetCostModel sets the cost model of all the cores. A nil model makes
+// every instruction take one cycle, which is the default. The cost model
+// is not saved in snapshots.
+func (m *Machine) SetCostModel(cm *CostModel) {
+	for _, c := range m.cores.cores {
+		c.cost = cm
+	}
+}
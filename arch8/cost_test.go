@@ -0,0 +1,127 @@
+package arch8
+
+import (
+	"testing"
+)
+
+func TestOpcode(t *testing.T) {
+	for _, test := range []struct {
+		in, op uint32
+	}{
+		{ADDI<<24 | 1<<21, ADDI},
+		{BEQ << 24, BEQ},
+		{HALT << 24, HALT},
+		{DIV | 1<<21, OpReg + DIV},
+		{FDIV | 1<<8, OpFloat + FDIV},
+		{JAL<<30 | 0x40, OpJump + JAL},
+		{J << 30, OpJump + J},
+	} {
+		if got := Opcode(test.in); got != test.op {
+			t.Errorf("opcode of %08x: got %x, want %x", test.in, got, test.op)
+		}
+	}
+}
+
+func TestCostModel(t *testing.T) {
+	addi := (ADDI << 24) | (R1 << 21) | 1
+	div := DIV | R2<<21 | R1<<18 | R1<<15
+	lw := (LW << 24) | (R3 << 21) | 0x1000
+
+	newMachine := func() *Machine {
+		m := NewMachine(PageSize*64, 1)
+		m.WriteWord(InitPC, uint32(addi))
+		m.WriteWord(InitPC+4, uint32(div))
+		m.WriteWord(InitPC+8, uint32(lw))
+		m.WriteWord(InitPC+12, HALT<<24)
+		return m
+	}
+
+	for _, test := range []struct {
+		cost  *CostModel
+		ticks int
+	}{
+		{nil, 4},
+		{&CostModel{}, 4},
+		{&CostModel{
+			Ops: map[uint32]uint32{OpReg + DIV: 5},
+			Mem: 2,
+		}, 10}, // 1 + 5 + 3 + 1
+	} {
+		m := newMachine()
+		m.SetCostModel(test.cost)
+		n, e := m.Run(100)
+		if e == nil || e.Code != ErrHalt {
+			t.Fatalf("got %v, want halt", e)
+		}
+		if n != test.ticks {
+			t.Errorf("halted after %d ticks, want %d", n, test.ticks)
+		}
+		if m.Reg(0, R2) != 1 {
+			t.Errorf("div got %d", m.Reg(0, R2))
+		}
+
+		perf := m.PerfCounters(0)
+		if perf.Cycles != uint64(n) || perf.Retired != 3 {
+			t.Errorf("got counters %+v", perf)
+		}
+
+		// the guest reads the counters in the sysinfo page
+		base := uint32(pageSysInfo*PageSize + sysPerf)
+		if w, _ := m.ReadWord(base + perfRetired); w != 3 {
+			t.Errorf("got %d retired in sysinfo page", w)
+		}
+	}
+}
+
+func TestPerfCounters(t *testing.T) {
+	m := NewMachine(PageSize*64, 1)
+	c := m.cores.cores[0]
+
+	// an empty page table faults the fetches
+	c.virtMem.SetTable(0x10000)
+	_, e := m.Run(1)
+	if e == nil || e.Code != ErrPageFault {
+		t.Fatalf("got %v, want page fault", e)
+	}
+	if perf := m.PerfCounters(0); perf.PageFaults != 1 {
+		t.Fatalf("got counters %+v", perf)
+	}
+
+	// an ipi enters the handler
+	c.virtMem.SetTable(0)
+	c.interrupt.writeWord(intHandlerSP, 0x30000)
+	c.interrupt.writeWord(intHandlerPC, InitPC)
+	c.interrupt.EnableInt(IntIPI)
+	c.interrupt.Enable()
+	c.interrupt.Issue(IntIPI)
+	if _, e := m.Run(1); e != nil {
+		t.Fatal(e)
+	}
+	perf := m.PerfCounters(0)
+	if perf.Interrupts != 1 || perf.Retired != 0 || perf.Cycles != 2 {
+		t.Fatalf("got counters %+v", perf)
+	}
+}
+
+func TestCostModelPageWalk(t *testing.T) {
+	m := NewMachine(PageSize*64, 1)
+	pte := func(ppn uint32) uint32 {
+		e := ptEntry(ppn * PageSize)
+		e.setBit(pteValid)
+		return uint32(e)
+	}
+	m.WriteWord(0x10000, pte(0x11))
+	m.WriteWord(0x11000+InitPC/PageSize*4, pte(InitPC/PageSize))
+	m.WriteWord(InitPC, (ADDI<<24)|(R1<<21)|1)
+	m.WriteWord(InitPC+4, HALT<<24)
+	m.cores.cores[0].virtMem.SetTable(0x10000)
+	m.SetCostModel(&CostModel{PageWalk: 10})
+
+	n, e := m.Run(100)
+	if e == nil || e.Code != ErrHalt {
+		t.Fatalf("got %v, want halt", e)
+	}
+	if n != 12 { // only the first fetch walks the page table
+		t.Fatalf("halted after %d ticks, want 12", n)
+	}
+}
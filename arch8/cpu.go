@@ -18,6 +18,18 @@ type cpu struct {
 
 	inst  inst
 	index byte
+	bus   intBus // for issuing ipis
+	state byte   // running, waiting or halted
+
+	cost  *CostModel // nil for one cycle per instruction
+	stall uint32     // ticks left for the last instruction
+	perf  *perf
+
+	fast      bool   // uses the fast interpreter
+	fetchPN   uint32 // the page of the last fast fetch
+	fetchPage *page
+
+	trace *coreTrace // not nil when tracing
 }
 
 // NewCPU creates a CPU with memroy and instruction binding
@@ -31,6 +43,7 @@ func newCPU(mem *phyMemory, i inst, index byte) *cpu {
 	ret.phyMem = mem
 	ret.virtMem = newVirtMemory(ret.phyMem)
 	ret.index = index
+	ret.perf = newPerf(mem, index)
 
 	intPage := ret.phyMem.Page(pageInterrupt) // page 1 is the interrupt page
 	if intPage == nil {
@@ -58,19 +71,40 @@ func (c *cpu) Reset() {
 	c.regs[PC] = InitPC
 	c.virtMem.SetTable(0)
 	c.ring = 0
+	c.state = coreRunning
+	c.stall = 0
 	c.interrupt.Disable()
 }
 
 func (c *cpu) tick() *Excep {
+	misses := c.virtMem.tlb.stats.Misses
 	pc := c.regs[PC]
-	inst, e := c.readWord(pc)
-	if e != nil {
+	var op *fastOp
+	if c.fast {
+		op = c.fetchFast(pc)
+	}
+
+	var inst uint32
+	var e *Excep
+	if op != nil {
+		inst = op.inst
+	} else if inst, e = c.virtMem.FetchWord(pc, c.ring); e != nil {
 		return e
 	}
 
 	c.regs[PC] = pc + 4
 	if c.inst != nil {
-		e = c.inst.I(c, inst)
+		if c.trace != nil {
+			c.trace.begin(c, pc, inst)
+		}
+		if op != nil {
+			e = op.exec(c, op)
+		} else {
+			e = c.inst.I(c, inst)
+		}
+		if c.trace != nil {
+			c.trace.end(c, e)
+		}
 
 		if e != nil {
 			c.regs[PC] = pc // restore saved original PC
@@ -78,6 +112,11 @@ func (c *cpu) tick() *Excep {
 		}
 	}
 
+	c.perf.inc(perfRetired)
+	if c.cost != nil {
+		walks := c.virtMem.tlb.stats.Misses - misses
+		c.stall = c.cost.cycles(inst, walks) - 1
+	}
 	return nil
 }
 
@@ -114,6 +153,10 @@ func (c *cpu) writeByte(addr uint32, v uint8) *Excep {
 
 // Ienter enters a interrupt routine.
 func (c *cpu) Ienter(code byte, arg uint32) *Excep {
+	if c.virtMem.resv != nil {
+		c.virtMem.resv.drop(c.index) // the handler runs in between
+	}
+
 	hsp := c.interrupt.handlerSP()
 	base := hsp - intFrameSize
 
@@ -141,6 +184,7 @@ func (c *cpu) Ienter(code byte, arg uint32) *Excep {
 		return e
 	}
 
+	c.perf.inc(perfInts)
 	c.interrupt.Disable()
 	c.regs[SP] = hsp
 	c.regs[RET] = c.regs[PC]
@@ -210,6 +254,15 @@ func (c *cpu) Iret() *Excep {
 // Tick executes one instruction, and increases the program counter
 // by 4 by default. If an exception is met, it will handle it.
 func (c *cpu) Tick() *Excep {
+	c.perf.inc(perfCycles)
+	if !c.awake() {
+		return nil // idling
+	}
+	if c.stall > 0 {
+		c.stall-- // still executing the last instruction
+		return nil
+	}
+
 	poll, code := c.interrupt.Poll()
 	if poll {
 		return c.Ienter(code, 0)
@@ -222,6 +275,9 @@ func (c *cpu) Tick() *Excep {
 	}
 
 	// proceed attempt failed, this is a fault.
+	if e.Code == ErrPageFault || e.Code == ErrPageNoExec {
+		c.perf.inc(perfFaults)
+	}
 	c.interrupt.Issue(e.Code)       // put the fault on to interrupt
 	poll, code = c.interrupt.Poll() // see if it is handlable
 	if poll {
@@ -0,0 +1,112 @@
+package arch8
+
+import (
+	"sort"
+)
+
+// watchpoint is a memory write watch point.
+type watchpoint struct {
+	addr uint32
+	virt bool // watching a virtual address rather than a physical one
+}
+
+// debugger stops the simulation on breakpoints and watchpoints.
+// Breakpoints are checked before an instruction is executed, and
+// watchpoints are checked after the instruction that writes the watched
+// address is executed.
+type debugger struct {
+	breaks  map[uint32]bool
+	watches map[watchpoint]bool
+
+	resume map[int]uint32 // cores that are resuming from a breakpoint
+	hit    *Excep         // watchpoint hit by the last instruction
+}
+
+func newDebugger() *debugger {
+	return &debugger{
+		breaks:  make(map[uint32]bool),
+		watches: make(map[watchpoint]bool),
+		resume:  make(map[int]uint32),
+	}
+}
+
+func newBreakpoint(pc uint32) *Excep {
+	ret := newExcep(ErrBreakpoint, "breakpoint")
+	ret.Arg = pc
+	return ret
+}
+
+func newWatchpoint(addr uint32) *Excep {
+	ret := newExcep(ErrWatchpoint, "watchpoint")
+	ret.Arg = addr
+	return ret
+}
+
+// memWrite is called by the virtual memory on every successful write of
+// n bytes at virtual address va, which is mapped to physical address pa.
+func (d *debugger) memWrite(va, pa, n uint32) {
+	if d.hit != nil {
+		return
+	}
+
+	for w := range d.watches {
+		addr := pa
+		if w.virt {
+			addr = va
+		}
+		if w.addr >= addr && w.addr-addr < n {
+			d.hit = newWatchpoint(w.addr)
+			return
+		}
+	}
+}
+
+// takeHit returns and clears the pending watchpoint hit.
+func (d *debugger) takeHit() *Excep {
+	ret := d.hit
+	d.hit = nil
+	return ret
+}
+
+// checkBreaks checks if any of the cores is about to execute an
+// instruction at a breakpoint, skipping the cores that are still stalled
+// on the last instruction. A core that was stopped by a breakpoint will
+// pass the same breakpoint on the next check.
+func (d *debugger) checkBreaks(cores []*cpu) *CoreExcep {
+	for i, c := range cores {
+		pc := c.regs[PC]
+		if !d.breaks[pc] || c.stall > 0 {
+			continue
+		}
+		if resume, found := d.resume[i]; found && resume == pc {
+			continue
+		}
+
+		d.resume[i] = pc
+		return &CoreExcep{i, newBreakpoint(pc)}
+	}
+
+	return nil
+}
+
+// passed clears the resuming state of all cores after a tick.
+func (d *debugger) passed() {
+	for i := range d.resume {
+		delete(d.resume, i)
+	}
+}
+
+func (d *debugger) breakpoints() []uint32 {
+	var ret []uint32
+	for pc := range d.breaks {
+		ret = append(ret, pc)
+	}
+	sort.Sort(addrList(ret))
+	return ret
+}
+
+type addrList []uint32
+
+func (l addrList) Len() int           { return len(l) }
+func (l addrList) Less(i, j int) bool { return l[i] < l[j] }
+func (l addrList) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
@@ -0,0 +1,44 @@
+package arch8
+
+import (
+	"testing"
+)
+
+func TestDebugger(t *testing.T) {
+	as := func(cond bool, s string, args ...interface{}) {
+		if !cond {
+			t.Fatalf(s, args...)
+		}
+	}
+
+	m := NewMachine(PageSize*64, 1)
+	m.WriteWord(InitPC, (ADDI<<24)|(1<<21)|(1<<18)|1)   // addi r1 r1 1
+	m.WriteWord(InitPC+4, (ADDI<<24)|(1<<21)|(1<<18)|1) // addi r1 r1 1
+	m.WriteWord(InitPC+8, (SW<<24)|(1<<21)|(2<<18))     // sw r1 r2
+	m.WriteWord(InitPC+12, HALT<<24)
+	m.SetReg(0, R2, 0x9000)
+
+	m.AddBreakpoint(InitPC + 4)
+	m.AddWatchpoint(0x9000, false)
+	as(len(m.Breakpoints()) == 1, "breakpoint not added")
+
+	_, e := m.Run(0)
+	as(IsErr(e, ErrBreakpoint), "expect breakpoint, got %v", e)
+	as(m.Reg(0, PC) == InitPC+4, "wrong pc: %08x", m.Reg(0, PC))
+	as(m.Reg(0, R1) == 1, "wrong r1: %d", m.Reg(0, R1))
+
+	e = m.StepCore(0)
+	as(e == nil, "unexpected exception: %v", e)
+	as(m.Reg(0, R1) == 2, "wrong r1: %d", m.Reg(0, R1))
+
+	_, e = m.Run(0)
+	as(IsErr(e, ErrWatchpoint), "expect watchpoint, got %v", e)
+	as(e.Arg == 0x9000, "wrong watch address: %08x", e.Arg)
+	w, err := m.ReadVirtWord(0, 0x9000)
+	as(err == nil && w == 2, "wrong memory: %d, %v", w, err)
+
+	m.RemoveWatchpoint(0x9000, false)
+	m.RemoveBreakpoint(InitPC + 4)
+	_, e = m.Run(0)
+	as(IsHalt(e), "expect halt, got %v", e)
+}
@@ -1,6 +1,132 @@
 package arch8
 
-// Device is a general interface of an pherical device.
-type device interface {
+import (
+	"fmt"
+)
+
+// Device is a general interface of a peripheral device. Tick is called
+// once on every machine tick, before the cores execute.
+type Device interface {
 	Tick()
 }
+
+// TickFunc is a device that calls the function on every tick.
+type TickFunc func()
+
+// Tick calls the function.
+func (f TickFunc) Tick() { f() }
+
+// IO pages are the physical pages for memory mapped device registers.
+const (
+	IOPageFirst = pageBasicIO
+	IOPageLast  = pageSysInfo - 1
+)
+
+// IORegion is a range of memory mapped registers of a device.
+type IORegion struct {
+	p *pageOffset
+
+	Page   uint32
+	Offset uint32
+	Size   uint32
+}
+
+func (r *IORegion) check(offset, n uint32) {
+	if offset >= r.Size || r.Size-offset < n {
+		panic("io region access out of range")
+	}
+}
+
+// ReadByte reads the byte at the offset of the region.
+func (r *IORegion) ReadByte(offset uint32) byte {
+	r.check(offset, 1)
+	return r.p.readByte(offset)
+}
+
+// WriteByte writes the byte at the offset of the region.
+func (r *IORegion) WriteByte(offset uint32, b byte) {
+	r.check(offset, 1)
+	r.p.writeByte(offset, b)
+}
+
+// ReadWord reads the word at the offset of the region. The offset must
+// be aligned to the word size together with the region's offset.
+func (r *IORegion) ReadWord(offset uint32) uint32 {
+	r.check(offset, 4)
+	return r.p.readWord(offset)
+}
+
+// WriteWord writes the word at the offset of the region.
+func (r *IORegion) WriteWord(offset uint32, w uint32) {
+	r.check(offset, 4)
+	r.p.writeWord(offset, w)
+}
+
+// MapIO reserves a range of memory mapped registers on an IO page for a
+// device. The range cannot overlap with the ranges of other devices.
+func (m *Machine) MapIO(page, offset, size uint32) (*IORegion, error) {
+	if page < IOPageFirst || page > IOPageLast {
+		return nil, fmt.Errorf("page %d is not an io page", page)
+	}
+	if size == 0 || offset >= PageSize || PageSize-offset < size {
+		return nil, fmt.Errorf("invalid io range %d+%d", offset, size)
+	}
+
+	for _, r := range m.ioRegions {
+		if r.Page != page {
+			continue
+		}
+		if offset < r.Offset+r.Size && r.Offset < offset+size {
+			return nil, fmt.Errorf(
+				"io range %d+%d on page %d overlaps with %d+%d",
+				offset, size, page, r.Offset, r.Size,
+			)
+		}
+	}
+
+	ret := &IORegion{
+		p:      &pageOffset{m.phyMem.Page(page), offset},
+		Page:   page,
+		Offset: offset,
+		Size:   size,
+	}
+	m.ioRegions = append(m.ioRegions, ret)
+	return ret, nil
+}
+
+// unmapIO releases a range reserved by MapIO.
+func (m *Machine) unmapIO(r *IORegion) {
+	for i, x := range m.ioRegions {
+		if x == r {
+			m.ioRegions = append(m.ioRegions[:i], m.ioRegions[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *Machine) mustMapIO(page, offset, size uint32) *IORegion {
+	ret, err := m.MapIO(page, offset, size)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// AddDevice adds a device to the machine. Devices are ticked in the
+// order they are added.
+func (m *Machine) AddDevice(d Device) {
+	m.devices = append(m.devices, d)
+}
+
+// Interrupt issues an interrupt to a core.
+func (m *Machine) Interrupt(code byte, core int) {
+	if core < 0 || core >= len(m.cores.cores) {
+		panic("out of cores")
+	}
+	m.cores.Interrupt(code, byte(core))
+}
+
+// InterruptAll issues an interrupt to all the cores.
+func (m *Machine) InterruptAll(code byte) {
+	intAllCores(m.cores, code)
+}
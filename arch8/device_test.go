@@ -0,0 +1,45 @@
+package arch8
+
+import (
+	"testing"
+)
+
+func TestDevice(t *testing.T) {
+	m := NewMachine(PageSize*64, 2)
+
+	if _, err := m.MapIO(pageBasicIO, serialBase+4, 4); err == nil {
+		t.Fatal("overlapping io range accepted")
+	}
+	if _, err := m.MapIO(pageSysInfo, 0, 4); err == nil {
+		t.Fatal("non-io page accepted")
+	}
+
+	r, err := m.MapIO(IOPageFirst+1, 16, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const code = 40
+	m.AddDevice(TickFunc(func() {
+		n := r.ReadWord(0) + 1
+		r.WriteWord(0, n)
+		if n == 3 {
+			m.Interrupt(code, 1)
+		}
+	}))
+
+	for i := 0; i < 3; i++ {
+		m.Tick()
+	}
+	w, err := m.ReadWord((IOPageFirst+1)*PageSize + 16)
+	if err != nil || w != 3 {
+		t.Fatalf("got counter %d, %v", w, err)
+	}
+
+	in := m.cores.cores[1].interrupt
+	in.Enable()
+	in.EnableInt(code)
+	if poll, got := in.Poll(); !poll || got != code {
+		t.Fatal("interrupt not issued")
+	}
+}
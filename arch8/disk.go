@@ -0,0 +1,211 @@
+package arch8
+
+import (
+	"io"
+	"log"
+	"os"
+)
+
+// disk registers
+const (
+	diskCmd   = 0 // command, set by cpu, cleared by hardware when accepted
+	diskState = 1 // idle or busy
+	diskErr   = 2 // error code of the last command
+
+	diskSector  = 4  // the first sector to read or write
+	diskCount   = 8  // number of sectors to read or write
+	diskAddr    = 12 // physical address to transfer from or to
+	diskNsector = 16 // total number of sectors, set by hardware
+
+	diskBase   = 0x180
+	diskIOSize = 20
+)
+
+const (
+	diskCmdIdle  = 0
+	diskCmdRead  = 1
+	diskCmdWrite = 2
+
+	diskStateIdle = 0
+	diskStateBusy = 1
+)
+
+const (
+	diskErrNone = iota
+	diskErrCmd
+	diskErrRange
+	diskErrMemory
+	diskErrIO
+)
+
+// DiskSectorSize is the number of bytes in a disk sector.
+const DiskSectorSize = 512
+
+// disk latency model, in ticks
+const (
+	diskSeekTicks   = 100 // for every command
+	diskSectorTicks = 20  // for every sector transferred
+)
+
+// disk is a writable block storage device backed by a host image file.
+// A command transfers sectors between the disk and the physical memory
+// with DMA, and raises an interrupt when it completes.
+type disk struct {
+	intBus intBus
+	p      *pageOffset
+	mem    *phyMemory
+
+	path    string
+	f       *os.File
+	nsector uint32
+
+	state     byte
+	cmd       byte
+	countDown int
+
+	Core    byte
+	IntDone byte
+}
+
+func newDisk(p *pageOffset, mem *phyMemory, i intBus, path string) (
+	*disk, error,
+) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	ret := &disk{
+		intBus:  i,
+		p:       p,
+		mem:     mem,
+		path:    path,
+		f:       f,
+		nsector: uint32(info.Size() / DiskSectorSize),
+		IntDone: IntDisk,
+	}
+	p.writeWord(diskNsector, ret.nsector)
+	return ret, nil
+}
+
+func (d *disk) interrupt(code byte) {
+	d.intBus.Interrupt(code, d.Core)
+}
+
+// check checks the command in the registers before accepting it.
+func (d *disk) check(cmd byte) byte {
+	if cmd != diskCmdRead && cmd != diskCmdWrite {
+		return diskErrCmd
+	}
+
+	sector := d.p.readWord(diskSector)
+	count := d.p.readWord(diskCount)
+	if sector > d.nsector || count > d.nsector-sector {
+		return diskErrRange
+	}
+
+	addr := d.p.readWord(diskAddr)
+	n := uint64(count) * DiskSectorSize
+	if uint64(addr)+n > d.mem.size64() {
+		return diskErrMemory
+	}
+	return diskErrNone
+}
+
+// transfer performs the accepted command.
+func (d *disk) transfer() byte {
+	sector := d.p.readWord(diskSector)
+	count := d.p.readWord(diskCount)
+	addr := d.p.readWord(diskAddr)
+	offset := int64(sector) * DiskSectorSize
+	buf := make([]byte, count*DiskSectorSize)
+
+	if d.cmd == diskCmdWrite {
+		for i := range buf {
+			b, e := d.mem.ReadByte(addr + uint32(i))
+			if e != nil {
+				return diskErrMemory
+			}
+			buf[i] = b
+		}
+		if _, err := d.f.WriteAt(buf, offset); err != nil {
+			log.Println(err)
+			return diskErrIO
+		}
+		return diskErrNone
+	}
+
+	if _, err := d.f.ReadAt(buf, offset); err != nil && err != io.EOF {
+		log.Println(err)
+		return diskErrIO
+	}
+	for i, b := range buf {
+		if e := d.mem.WriteByte(addr+uint32(i), b); e != nil {
+			return diskErrMemory
+		}
+	}
+	return diskErrNone
+}
+
+func (d *disk) done(errCode byte) {
+	d.p.writeByte(diskErr, errCode)
+	d.state = diskStateIdle
+	d.cmd = diskCmdIdle
+	d.interrupt(d.IntDone)
+}
+
+func (d *disk) Tick() {
+	switch d.state {
+	case diskStateIdle:
+		cmd := d.p.readByte(diskCmd)
+		if cmd == diskCmdIdle {
+			break
+		}
+		d.p.writeByte(diskCmd, diskCmdIdle)
+
+		if errCode := d.check(cmd); errCode != diskErrNone {
+			d.done(errCode)
+			break
+		}
+
+		d.cmd = cmd
+		d.state = diskStateBusy
+		count := d.p.readWord(diskCount)
+		d.countDown = diskSeekTicks + diskSectorTicks*int(count)
+	case diskStateBusy:
+		if d.countDown > 0 {
+			d.countDown--
+		} else {
+			d.done(d.transfer())
+		}
+	}
+
+	d.p.writeByte(diskState, d.state)
+}
+
+// Close closes the host image file.
+func (d *disk) Close() error { return d.f.Close() }
+
+// AttachDisk attaches a writable disk backed by a host image file. The
+// size of the disk is the size of the file rounded down to sectors.
+// Only one disk can be attached. The image file is closed when the
+// machine is closed.
+func (m *Machine) AttachDisk(path string) error {
+	p, err := m.MapIO(pageBasicIO, diskBase, diskIOSize)
+	if err != nil {
+		return err
+	}
+	d, err := newDisk(p.p, m.phyMem, m.cores, path)
+	if err != nil {
+		m.unmapIO(p)
+		return err
+	}
+	m.disk = d
+	m.AddDevice(d)
+	return nil
+}
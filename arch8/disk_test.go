@@ -0,0 +1,94 @@
+package arch8
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func diskRun(t *testing.T, m *Machine, cmd byte, sector, addr uint32) {
+	const base = pageBasicIO*PageSize + diskBase
+	m.WriteWord(base+diskSector, sector)
+	m.WriteWord(base+diskCount, 1)
+	m.WriteWord(base+diskAddr, addr)
+	m.WriteByte(base+diskCmd, cmd)
+
+	for i := 0; i < diskSeekTicks+diskSectorTicks+3; i++ {
+		m.Tick()
+	}
+	if state, _ := m.ReadByte(base + diskState); state != diskStateIdle {
+		t.Fatal("disk command not finished")
+	}
+	if e, _ := m.ReadByte(base + diskErr); e != diskErrNone {
+		t.Fatalf("disk command failed with %d", e)
+	}
+}
+
+func testDisk(t *testing.T, memSize uint32) {
+	f, err := ioutil.TempFile("", "e8disk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if err := f.Truncate(4 * DiskSectorSize); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	m := NewMachine(memSize, 1)
+	if err := m.AttachDisk(f.Name() + ".missing"); err == nil {
+		t.Fatal("attached a missing disk image")
+	}
+	if err := m.AttachDisk(f.Name()); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	n, _ := m.ReadWord(pageBasicIO*PageSize + diskBase + diskNsector)
+	if n != 4 {
+		t.Fatalf("got %d sectors, want 4", n)
+	}
+
+	for i := uint32(0); i < DiskSectorSize; i++ {
+		m.WriteByte(0x9000+i, byte(i))
+	}
+	diskRun(t, m, diskCmdWrite, 2, 0x9000)
+	diskRun(t, m, diskCmdRead, 2, 0xa000)
+
+	want := make([]byte, DiskSectorSize)
+	got := make([]byte, DiskSectorSize)
+	for i := range want {
+		want[i] = byte(i)
+		got[i], _ = m.ReadByte(0xa000 + uint32(i))
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("sector read back differs")
+	}
+
+	bs, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bs[2*DiskSectorSize:3*DiskSectorSize], want) {
+		t.Fatal("sector not written to the image file")
+	}
+
+	in := m.cores.cores[0].interrupt
+	in.Enable()
+	in.EnableInt(IntDisk)
+	if poll, code := in.Poll(); !poll || code != IntDisk {
+		t.Fatal("disk interrupt not issued")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.disk.f.Close(); err == nil {
+		t.Fatal("image file not closed")
+	}
+}
+
+func TestDisk(t *testing.T) {
+	testDisk(t, PageSize*64)
+	testDisk(t, 0) // full 4GB memory
+}
@@ -0,0 +1,61 @@
+package arch8
+
+// ExcepReport is the context of a core when an exception is thrown out of
+// it, for reporting the exception.
+type ExcepReport struct {
+	*CoreExcep
+
+	PC     uint32
+	Inst   uint32 // the instruction at PC, valid when InstOK
+	InstOK bool
+	Ring   byte
+	Regs   [Nreg]uint32
+
+	VirtAddr   uint32 // the memory address accessed, valid when HasAddr
+	PhyAddr    uint32 // valid when HasPhyAddr
+	HasAddr    bool
+	HasPhyAddr bool
+}
+
+func isMemExcep(code byte) bool {
+	switch code {
+	case ErrOutOfRange, ErrMisalign, ErrPageFault, ErrPageReadonly,
+		ErrPageNoExec:
+		return true
+	}
+	return false
+}
+
+// ExcepReport returns the context of the core that throws an exception,
+// which must be the last exception returned by Run, Tick or StepCore.
+// The registers are those when the faulting instruction is about to
+// execute.
+func (m *Machine) ExcepReport(e *CoreExcep) *ExcepReport {
+	c := m.cores.cores[e.Core]
+	ret := &ExcepReport{
+		CoreExcep: e,
+		PC:        c.regs[PC],
+		Ring:      c.ring,
+	}
+	copy(ret.Regs[:], c.regs)
+
+	if in, err := m.ReadVirtWord(e.Core, ret.PC); err == nil {
+		ret.Inst = in
+		ret.InstOK = true
+	}
+
+	if !isMemExcep(e.Code) {
+		return ret
+	}
+	f := c.virtMem.fault
+	ret.VirtAddr = f.va
+	ret.HasAddr = true
+	if f.hasPA {
+		ret.PhyAddr = f.pa
+		ret.HasPhyAddr = true
+	} else if pa, err := m.PhyAddr(e.Core, f.va); err == nil {
+		ret.PhyAddr = pa
+		ret.HasPhyAddr = true
+	}
+	return ret
+}
@@ -0,0 +1,50 @@
+package arch8
+
+import (
+	"testing"
+)
+
+func TestExcepReport(t *testing.T) {
+	m := NewMachine(PageSize*64, 1)
+	lw := uint32((LW << 24) | (R2 << 21) | (R1 << 18) | 4)
+	m.WriteWord(InitPC, lw)
+	m.SetReg(0, R1, 0x100000)
+
+	_, e := m.Run(10)
+	if e == nil || e.Code != ErrOutOfRange {
+		t.Fatalf("got %v, want out of range", e)
+	}
+	r := m.ExcepReport(e)
+	if r.PC != InitPC || !r.InstOK || r.Inst != lw {
+		t.Errorf("got pc %08x, inst %08x", r.PC, r.Inst)
+	}
+	if r.Regs[R1] != 0x100000 || r.Ring != 0 {
+		t.Errorf("got r1 %08x, ring %d", r.Regs[R1], r.Ring)
+	}
+	if !r.HasAddr || r.VirtAddr != 0x100004 {
+		t.Errorf("got virtual address %08x", r.VirtAddr)
+	}
+	if !r.HasPhyAddr || r.PhyAddr != 0x100004 {
+		t.Errorf("got physical address %08x", r.PhyAddr)
+	}
+
+	// fetching with an empty page table
+	m = NewMachine(PageSize*64, 1)
+	m.cores.cores[0].virtMem.SetTable(0x10000)
+	_, e = m.Run(10)
+	if e == nil || e.Code != ErrPageFault {
+		t.Fatalf("got %v, want page fault", e)
+	}
+	r = m.ExcepReport(e)
+	if r.InstOK || !r.HasAddr || r.VirtAddr != InitPC || r.HasPhyAddr {
+		t.Errorf("got report %+v", r)
+	}
+
+	// not a memory exception
+	m = NewMachine(PageSize*64, 1)
+	m.WriteWord(InitPC, HALT<<24)
+	_, e = m.Run(10)
+	if r := m.ExcepReport(e); r.HasAddr || r.Inst != HALT<<24 {
+		t.Errorf("got report %+v", r)
+	}
+}
@@ -36,11 +36,22 @@ const (
 	ErrMisalign     = 5
 	ErrPageFault    = 6
 	ErrPageReadonly = 7
+	ErrPageNoExec   = 13 // fetch from a no-exec page; 10-12 are simulator stops
 	ErrPanic        = 8
+	ErrFloat        = 9
+
+	// simulator stops, never delivered to the guest
+	ErrBreakpoint = 10
+	ErrWatchpoint = 11
+	ErrInputLog   = 12
 
 	IntSerial = 16
 	IntROM    = 17
 	IntSwap   = 18
+	IntDisk   = 19
+	IntNIC    = 20
+	IntTimer  = 21
+	IntIPI    = 22
 )
 
 var (
@@ -50,6 +61,7 @@ var (
 
 	errMisalign = newExcep(ErrMisalign, "address misalign")
 	errPanic    = newExcep(ErrPanic, "panic")
+	errFloat    = newExcep(ErrFloat, "float exception")
 )
 
 func newPageFault(va uint32) *Excep {
@@ -64,6 +76,12 @@ func newPageReadonly(va uint32) *Excep {
 	return ret
 }
 
+func newPageNoExec(va uint32) *Excep {
+	ret := newExcep(ErrPageNoExec, "page not executable")
+	ret.Arg = va
+	return ret
+}
+
 func newOutOfRange(pa uint32) *Excep {
 	ret := newExcep(ErrOutOfRange, "out of range")
 	ret.Arg = pa
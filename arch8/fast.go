@@ -0,0 +1,217 @@
+package arch8
+
+// fastOp is a pre-decoded instruction.
+type fastOp struct {
+	exec func(c *cpu, op *fastOp) *Excep // nil when not decoded yet
+
+	inst      uint32
+	d, s1, s2 uint32 // register indices
+	im        uint32 // immediate, shift, or branch offset
+}
+
+// codePage caches the pre-decoded instructions of a physical page.
+// A write into the page drops the instruction of the word written.
+type codePage struct {
+	ops [PageSize / 4]fastOp
+}
+
+// decodeBlock decodes the instructions from index i until the end of the
+// basic block or the page.
+func (cp *codePage) decodeBlock(p *page, i uint32) {
+	for ; i < PageSize/4; i++ {
+		op := &cp.ops[i]
+		if op.exec != nil {
+			return // decoded already
+		}
+		decode(op, p.uints[i])
+		if endsBlock(op) {
+			return
+		}
+	}
+}
+
+// endsBlock checks if the instruction might not continue to the next.
+func endsBlock(op *fastOp) bool {
+	in := op.inst
+	if in>>31 != 0 {
+		return true // jumps
+	}
+	return (in>>24)&0xff >= 32 || op.d == PC
+}
+
+func decode(op *fastOp, in uint32) {
+	*op = fastOp{
+		exec: execSlow,
+		inst: in,
+		d:    (in >> 21) & 0x7,
+		s1:   (in >> 18) & 0x7,
+		s2:   (in >> 15) & 0x7,
+	}
+
+	if in>>31 != 0 {
+		op.im = (in & 0x3fffffff) << 2
+		switch (in >> 30) & 0x3 {
+		case J:
+			op.exec = execJ
+		case JAL:
+			op.exec = execJAL
+		}
+		return
+	}
+
+	switch code := (in >> 24) & 0xff; {
+	case code == 0:
+		decodeReg(op, in)
+	case code < 32:
+		decodeImm(op, code, in)
+	case code < 64:
+		op.im = uint32(int32((in&0x3ffff)<<14) >> 12)
+		switch code {
+		case BNE:
+			op.exec = execBNE
+		case BEQ:
+			op.exec = execBEQ
+		}
+	}
+	// system instructions take the slow path
+}
+
+func decodeImm(op *fastOp, code, in uint32) {
+	imu := in & 0xffff
+	op.im = uint32(int32(imu<<16) >> 16)
+	switch code {
+	case ADDI:
+		op.exec = execADDI
+	case SLTI:
+		op.exec = execSLTI
+	case ANDI:
+		op.im = imu
+		op.exec = execANDI
+	case ORI:
+		op.im = imu
+		op.exec = execORI
+	case XORI:
+		op.im = imu
+		op.exec = execXORI
+	case LUI:
+		op.im = imu << 16
+		op.exec = execLUI
+	case LW:
+		op.exec = execLW
+	case LB:
+		op.exec = execLB
+	case LBU:
+		op.exec = execLBU
+	case SW:
+		op.exec = execSW
+	case SB:
+		op.exec = execSB
+	}
+}
+
+func decodeReg(op *fastOp, in uint32) {
+	if (in>>8)&0x1 != 0 {
+		return // floats take the slow path
+	}
+	op.im = (in >> 10) & 0x1f
+	if f, ok := fastRegOps[in&0xff]; ok {
+		op.exec = f
+	}
+}
+
+var fastRegOps = map[uint32]func(*cpu, *fastOp) *Excep{
+	SLL: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = c.regs[op.s1] << op.im
+		return nil
+	},
+	SRL: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = c.regs[op.s1] >> op.im
+		return nil
+	},
+	SRA: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = uint32(int32(c.regs[op.s1]) >> op.im)
+		return nil
+	},
+	ADD: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = c.regs[op.s1] + c.regs[op.s2]
+		return nil
+	},
+	SUB: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = c.regs[op.s1] - c.regs[op.s2]
+		return nil
+	},
+	AND: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = c.regs[op.s1] & c.regs[op.s2]
+		return nil
+	},
+	OR: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = c.regs[op.s1] | c.regs[op.s2]
+		return nil
+	},
+	XOR: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = c.regs[op.s1] ^ c.regs[op.s2]
+		return nil
+	},
+	SLTU: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = boolWord(c.regs[op.s1] < c.regs[op.s2])
+		return nil
+	},
+	SLT: func(c *cpu, op *fastOp) *Excep {
+		s1, s2 := int32(c.regs[op.s1]), int32(c.regs[op.s2])
+		c.regs[op.d] = boolWord(s1 < s2)
+		return nil
+	},
+	MULU: func(c *cpu, op *fastOp) *Excep {
+		c.regs[op.d] = c.regs[op.s1] * c.regs[op.s2]
+		return nil
+	},
+}
+
+func boolWord(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// fetchFast returns the pre-decoded instruction at pc, or nil if the
+// fetch fails and needs to take the slow path for the exception.
+func (c *cpu) fetchFast(pc uint32) *fastOp {
+	if pc%4 != 0 {
+		return nil
+	}
+	pa, e := c.virtMem.transExec(pc, c.ring)
+	if e != nil {
+		return nil
+	}
+
+	pn := pa / PageSize
+	if c.fetchPN != pn || c.fetchPage == nil {
+		c.fetchPage = c.phyMem.Page(pn)
+		c.fetchPN = pn
+		if c.fetchPage == nil {
+			return nil
+		}
+	}
+
+	p := c.fetchPage
+	if p.code == nil {
+		p.code = new(codePage)
+	}
+	i := (pa % PageSize) / 4
+	if p.code.ops[i].exec == nil {
+		p.code.decodeBlock(p, i)
+	}
+	return &p.code.ops[i]
+}
+
+// SetFast turns on or off the fast interpreter on all the cores, which
+// pre-decodes the instructions by basic blocks and caches them for each
+// physical page. Instruction fetches that fail, and the instructions
+// that are rarely used, still take the slow path. The two interpreters
+// behave exactly the same.
+func (m *Machine) SetFast(on bool) {
+	for _, c := range m.cores.cores {
+		c.fast = on
+	}
+}
@@ -0,0 +1,98 @@
+package arch8
+
+// the fast paths of the pre-decoded instructions; they must behave
+// exactly the same as instArch8
+
+func execSlow(c *cpu, op *fastOp) *Excep {
+	return c.inst.I(c, op.inst)
+}
+
+func execADDI(c *cpu, op *fastOp) *Excep {
+	c.regs[op.d] = c.regs[op.s1] + op.im
+	return nil
+}
+
+func execSLTI(c *cpu, op *fastOp) *Excep {
+	c.regs[op.d] = boolWord(int32(c.regs[op.s1]) < int32(op.im))
+	return nil
+}
+
+func execANDI(c *cpu, op *fastOp) *Excep {
+	c.regs[op.d] = c.regs[op.s1] & op.im
+	return nil
+}
+
+func execORI(c *cpu, op *fastOp) *Excep {
+	c.regs[op.d] = c.regs[op.s1] | op.im
+	return nil
+}
+
+func execXORI(c *cpu, op *fastOp) *Excep {
+	c.regs[op.d] = c.regs[op.s1] ^ op.im
+	return nil
+}
+
+func execLUI(c *cpu, op *fastOp) *Excep {
+	c.regs[op.d] = op.im
+	return nil
+}
+
+func execLW(c *cpu, op *fastOp) *Excep {
+	v, e := c.readWord(c.regs[op.s1] + op.im)
+	if e != nil {
+		return e
+	}
+	c.regs[op.d] = v
+	return nil
+}
+
+func execLB(c *cpu, op *fastOp) *Excep {
+	b, e := c.readByte(c.regs[op.s1] + op.im)
+	if e != nil {
+		return e
+	}
+	c.regs[op.d] = uint32(int32(int8(b)))
+	return nil
+}
+
+func execLBU(c *cpu, op *fastOp) *Excep {
+	b, e := c.readByte(c.regs[op.s1] + op.im)
+	if e != nil {
+		return e
+	}
+	c.regs[op.d] = uint32(b)
+	return nil
+}
+
+func execSW(c *cpu, op *fastOp) *Excep {
+	return c.writeWord(c.regs[op.s1]+op.im, c.regs[op.d])
+}
+
+func execSB(c *cpu, op *fastOp) *Excep {
+	return c.writeByte(c.regs[op.s1]+op.im, byte(c.regs[op.d]))
+}
+
+func execBNE(c *cpu, op *fastOp) *Excep {
+	if c.regs[op.d] != c.regs[op.s1] {
+		c.regs[PC] += op.im
+	}
+	return nil
+}
+
+func execBEQ(c *cpu, op *fastOp) *Excep {
+	if c.regs[op.d] == c.regs[op.s1] {
+		c.regs[PC] += op.im
+	}
+	return nil
+}
+
+func execJ(c *cpu, op *fastOp) *Excep {
+	c.regs[PC] += op.im
+	return nil
+}
+
+func execJAL(c *cpu, op *fastOp) *Excep {
+	c.regs[RET] = c.regs[PC]
+	c.regs[PC] += op.im
+	return nil
+}
@@ -0,0 +1,90 @@
+package arch8
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func randInst(r *rand.Rand) uint32 {
+	reg := func() uint32 { return uint32(r.Intn(5)) }
+	switch r.Intn(6) {
+	case 0, 1:
+		op := uint32(ADDI + r.Intn(SC))
+		im := uint32(r.Intn(0x10000))
+		if op >= LW {
+			im = uint32(r.Intn(0x200)-0x100) & 0xffff
+		}
+		return op<<24 | reg()<<21 | reg()<<18 | im
+	case 2:
+		in := reg()<<21 | reg()<<18 | reg()<<15 | uint32(r.Intn(8))<<10
+		if r.Intn(8) == 0 {
+			in |= 1 << 8
+		}
+		return in | uint32(r.Intn(XADD+2))
+	case 3:
+		op := uint32(BNE + r.Intn(2))
+		return op<<24 | reg()<<21 | reg()<<18 | uint32(r.Intn(16)-8)&0x3ffff
+	case 4:
+		op := uint32(J + r.Intn(2))
+		return op<<30 | uint32(r.Intn(16)-8)&0x3fffffff
+	}
+	return r.Uint32()
+}
+
+func sameMemory(t *testing.T, m1, m2 *Machine) {
+	for pn, p1 := range m1.phyMem.pages {
+		p2 := m2.phyMem.Page(pn)
+		if !reflect.DeepEqual(p1.uints, p2.uints) {
+			t.Fatalf("page %d differs", pn)
+		}
+	}
+	for pn := range m2.phyMem.pages {
+		if m1.phyMem.pages[pn] == nil {
+			t.Fatalf("page %d only in the fast machine", pn)
+		}
+	}
+}
+
+func TestFastDiff(t *testing.T) {
+	r := rand.New(rand.NewSource(8))
+	for prog := 0; prog < 200; prog++ {
+		m1 := NewMachine(PageSize*64, 1)
+		m2 := NewMachine(PageSize*64, 1)
+		m2.SetFast(true)
+		for i := uint32(0); i < 256; i++ {
+			in := randInst(r)
+			m1.WriteWord(InitPC+i*4, in)
+			m2.WriteWord(InitPC+i*4, in)
+		}
+		for _, m := range []*Machine{m1, m2} {
+			m.SetReg(0, R2, 0x9000)
+			m.SetReg(0, R3, 3)
+			m.SetReg(0, R4, InitPC+0x200) // stores into the code
+		}
+
+		for i := 0; i < 2000; i++ {
+			e1 := m1.Tick()
+			e2 := m2.Tick()
+			if !reflect.DeepEqual(e1, e2) {
+				t.Fatalf("prog %d tick %d: got %v, want %v",
+					prog, i, e2, e1,
+				)
+			}
+			c1, c2 := m1.cores.cores[0], m2.cores.cores[0]
+			if !reflect.DeepEqual(c1.regs, c2.regs) || c1.ring != c2.ring {
+				t.Fatalf("prog %d tick %d: registers differ", prog, i)
+			}
+			if e1 != nil {
+				// skip the faulting instruction, and restart if lost
+				for _, c := range []*cpu{c1, c2} {
+					c.regs[PC] += 4
+					if c.regs[PC] >= InitPC+0x400 {
+						c.regs[PC] = InitPC
+					}
+				}
+			}
+		}
+		sameMemory(t, m1, m2)
+	}
+}
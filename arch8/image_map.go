@@ -0,0 +1,124 @@
+package arch8
+
+import (
+	"errors"
+	"fmt"
+
+	"e8vm.io/e8vm/e8"
+)
+
+// pageAlloc allocates the physical pages upwards.
+type pageAlloc struct {
+	mem  *phyMemory
+	next uint32 // page number
+}
+
+func (a *pageAlloc) alloc() (uint32, *page, error) {
+	pn := a.next
+	p := a.mem.Page(pn)
+	if p == nil {
+		return 0, nil, errors.New("out of physical pages")
+	}
+	a.next++
+	p.clear()
+	return pn, p, nil
+}
+
+// npageAlloc counts the physical pages that mapping the pages allocates,
+// which is a page for each page mapped and each second level page table.
+func npageAlloc(pages []*e8.PageMap) uint64 {
+	tables := make(map[uint32]bool)
+	for _, pm := range pages {
+		tables[pm.VPN/1024] = true
+	}
+	return uint64(len(pages) + len(tables))
+}
+
+// MapImage maps the pages of an image into the address space of the page
+// table at root, which is a physical page that is cleared first. The
+// contents of the pages and the second level page tables are written into
+// the physical pages allocated upwards from free. It returns the address
+// of the next free physical page. The page table at root cannot be one of
+// the allocated pages.
+//
+// Readonly pages are mapped readonly, and pages that are not executable
+// are mapped with the no-execute bit, so that fetching instructions from
+// data pages throws a page-not-executable exception.
+func (m *Machine) MapImage(pages []*e8.PageMap, root, free uint32) (
+	uint32, error,
+) {
+	if root%PageSize != 0 || free%PageSize != 0 {
+		return 0, errors.New("page table or free pages not aligned")
+	}
+	rootPage := m.phyMem.Page(root / PageSize)
+	if rootPage == nil {
+		return 0, fmt.Errorf("page table at %08x out of range", root)
+	}
+
+	rootPN, freePN := uint64(root/PageSize), uint64(free/PageSize)
+	if rootPN >= freePN && rootPN < freePN+npageAlloc(pages) {
+		return 0, fmt.Errorf("page table at %08x in the pages to allocate",
+			root,
+		)
+	}
+	rootPage.clear()
+
+	a := &pageAlloc{mem: m.phyMem, next: free / PageSize}
+	tables := make(map[uint32]*page)
+	for _, pm := range pages {
+		if len(pm.Bytes) != PageSize {
+			return 0, fmt.Errorf("page %d has %d bytes",
+				pm.VPN, len(pm.Bytes),
+			)
+		}
+		if pm.VPN >= 1<<20 {
+			return 0, fmt.Errorf("page %d out of range", pm.VPN)
+		}
+
+		index1, index2 := pm.VPN/1024, pm.VPN%1024
+		table := tables[index1]
+		if table == nil {
+			pn, p, err := a.alloc()
+			if err != nil {
+				return 0, err
+			}
+			table = p
+			tables[index1] = p
+
+			pte1 := ptEntry(pn * PageSize)
+			pte1.setBit(pteValid)
+			pte1.setBit(pteUser)
+			rootPage.WriteWord(index1*4, uint32(pte1))
+		}
+
+		pn, p, err := a.alloc()
+		if err != nil {
+			return 0, err
+		}
+		p.WriteAt(pm.Bytes, 0)
+
+		pte2 := ptEntry(pn * PageSize)
+		pte2.setBit(pteValid)
+		if pm.Readonly {
+			pte2.setBit(pteReadonly)
+		}
+		if pm.User {
+			pte2.setBit(pteUser)
+		}
+		if !pm.Exec {
+			pte2.setBit(pteNoExec)
+		}
+		table.WriteWord(index2*4, uint32(pte2))
+	}
+
+	return a.next * PageSize, nil
+}
+
+// SetAddrSpace sets all the cores to use the page table at root and run
+// in the ring. Setting the page table also flushes the tlbs of the cores.
+func (m *Machine) SetAddrSpace(root uint32, ring byte) {
+	for _, c := range m.cores.cores {
+		c.virtMem.SetTable(root)
+		c.ring = ring
+	}
+}
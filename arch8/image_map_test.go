@@ -0,0 +1,93 @@
+package arch8
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"e8vm.io/e8vm/e8"
+)
+
+func TestMapImage(t *testing.T) {
+	imm := func(op, d, s, im uint32) uint32 {
+		return op<<24 | d<<21 | s<<18 | im&0xffff
+	}
+	words := func(ws ...uint32) []byte {
+		ret := make([]byte, len(ws)*4)
+		for i, w := range ws {
+			binary.LittleEndian.PutUint32(ret[i*4:], w)
+		}
+		return ret
+	}
+	code := words(
+		imm(LW, R2, R1, 0),
+		imm(SW, R2, R1, 4),
+		HALT<<24,
+		imm(ADDI, PC, R1, 0),
+	)
+	secs := []*e8.Section{{
+		Header: &e8.Header{
+			Type: e8.Code,
+			Flag: e8.FlagReadonly | e8.FlagExec,
+			Addr: 0x8000,
+		},
+		Bytes: code,
+	}, {
+		Header: &e8.Header{Type: e8.Data, Addr: 0x9000},
+		Bytes:  words(0x12345678),
+	}}
+	for _, s := range secs {
+		s.Size = uint32(len(s.Bytes))
+	}
+
+	const root = 0x20000
+	run := func(pc, r1 uint32, fast bool) (*Machine, *CoreExcep) {
+		m := NewMachine(PageSize*64, 1)
+		m.SetFast(fast)
+		pages, err := e8.MapPages(secs, PageSize, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, bad := range []uint32{root, root - 2*PageSize} {
+			if _, err := m.MapImage(pages, root, bad); err == nil {
+				t.Errorf("allocating from %08x over the table", bad)
+			}
+		}
+		free, err := m.MapImage(pages, root, root+PageSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if free != root+4*PageSize { // a table and two pages
+			t.Errorf("got next free page %08x", free)
+		}
+		m.SetAddrSpace(root, 1)
+		m.SetPC(pc)
+		m.SetReg(0, R1, r1)
+		_, e := m.Run(100)
+		return m, e
+	}
+
+	for _, fast := range []bool{false, true} {
+		m, e := run(0x8000, 0x9000, fast)
+		if e == nil || e.Code != ErrHalt {
+			t.Fatalf("fast %t: got %v, want halt", fast, e)
+		}
+		if w, err := m.ReadVirtWord(0, 0x9004); err != nil ||
+			w != 0x12345678 {
+			t.Errorf("fast %t: got %08x, %v", fast, w, err)
+		}
+
+		// writing the code
+		_, e = run(0x8000, 0x8000, fast)
+		if e == nil || e.Code != ErrPageReadonly {
+			t.Errorf("fast %t: got %v, want page read-only", fast, e)
+		}
+
+		// jumping to the data
+		_, e = run(0x800c, 0x9000, fast)
+		if e == nil || e.Code != ErrPageNoExec || e.Arg != 0x9000 {
+			t.Errorf("fast %t: got %v, want page not executable",
+				fast, e,
+			)
+		}
+	}
+}
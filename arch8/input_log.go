@@ -0,0 +1,224 @@
+package arch8
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// input log file format
+//
+// header: magic "e8rr", version
+// events: tick, kind, arg, length of bytes, bytes
+//
+// All numbers are little endian.
+
+var inputLogMagic = [4]byte{'e', '8', 'r', 'r'}
+
+// InputLogVersion is the version of the input log format.
+const InputLogVersion = 1
+
+// kinds of external input events
+const (
+	inputSerial = 1 // a byte from the host to the serial input
+	inputTicker = 2 // the interval to the next time interrupt
+	inputROM    = 3 // the error code and bytes of a rom read
+	inputNIC    = 4 // a packet received by the nic
+	inputClock  = 5 // the wall clock latched by the rtc
+)
+
+type inputEvent struct {
+	tick  uint64
+	kind  byte
+	arg   uint32
+	bytes []byte
+}
+
+type inputEventHeader struct {
+	Tick uint64
+	Kind byte
+	Arg  uint32
+	N    uint32
+}
+
+// inputLog records or replays the events that are sourced externally.
+type inputLog struct {
+	tick uint64 // number of ticks simulated
+
+	w io.Writer // not nil when recording
+
+	events []*inputEvent // events to replay
+	pos    int
+
+	exp *Excep // error met in recording or replaying
+}
+
+func (l *inputLog) recording() bool { return l != nil && l.w != nil }
+func (l *inputLog) replaying() bool {
+	return l != nil && l.w == nil && l.pos < len(l.events)
+}
+
+func (l *inputLog) fail(s string) {
+	if l.exp != nil {
+		return
+	}
+	l.exp = newExcep(ErrInputLog, s)
+	l.exp.Arg = uint32(l.tick)
+}
+
+func (l *inputLog) record(kind byte, arg uint32, bs []byte) {
+	if l.exp != nil {
+		return
+	}
+
+	h := &inputEventHeader{l.tick, kind, arg, uint32(len(bs))}
+	if err := binary.Write(l.w, Endian, h); err != nil {
+		l.fail(fmt.Sprintf("record: %s", err))
+		return
+	}
+	if _, err := l.w.Write(bs); err != nil {
+		l.fail(fmt.Sprintf("record: %s", err))
+	}
+}
+
+// next returns the next event if it is of kind and happens at the
+// current tick.
+func (l *inputLog) next(kind byte) (*inputEvent, bool) {
+	if l.pos >= len(l.events) {
+		return nil, false
+	}
+	ev := l.events[l.pos]
+	if ev.tick != l.tick || ev.kind != kind {
+		return nil, false
+	}
+	l.pos++
+	return ev, true
+}
+
+// expect returns the next event, which must be of kind and happen at the
+// current tick, or the replay has diverged.
+func (l *inputLog) expect(kind byte) (*inputEvent, bool) {
+	ev, ok := l.next(kind)
+	if !ok {
+		l.fail("replay diverged")
+	}
+	return ev, ok
+}
+
+// maxInputBytes limits the length of the bytes of an event.
+const maxInputBytes = 1 << 24
+
+func readInputLog(r io.Reader) ([]*inputEvent, error) {
+	br := bufio.NewReader(r)
+	var magic [4]byte
+	var version uint32
+	if err := binary.Read(br, Endian, &magic); err != nil {
+		return nil, err
+	}
+	if magic != inputLogMagic {
+		return nil, errors.New("not an input log")
+	}
+	if err := binary.Read(br, Endian, &version); err != nil {
+		return nil, err
+	}
+	if version != InputLogVersion {
+		return nil, fmt.Errorf("unsupported input log version %d", version)
+	}
+
+	var ret []*inputEvent
+	for {
+		var h inputEventHeader
+		err := binary.Read(br, Endian, &h)
+		if err == io.EOF {
+			return ret, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if h.N > maxInputBytes {
+			return nil, errors.New("input event too long")
+		}
+
+		ev := &inputEvent{tick: h.Tick, kind: h.Kind, arg: h.Arg}
+		if h.N > 0 {
+			ev.bytes = make([]byte, h.N)
+			if _, err := io.ReadFull(br, ev.bytes); err != nil {
+				return nil, err
+			}
+		}
+		ret = append(ret, ev)
+	}
+}
+
+// setInputLog attaches the input log to the machine and its devices.
+func (m *Machine) setInputLog(l *inputLog) {
+	l.tick = m.ntick
+	m.input = l
+	m.ticker.input = l
+	m.rtc.input = l
+	if m.rom != nil {
+		m.rom.input = l
+	}
+	if m.nic != nil {
+		m.nic.input = l
+	}
+}
+
+// Record starts recording all the externally sourced events into w,
+// including serial input from the host, rom reads, received packets,
+// wall clock reads and time interrupt intervals. Each write to w writes
+// a complete event.
+func (m *Machine) Record(w io.Writer) error {
+	if err := binary.Write(w, Endian, inputLogMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, Endian, uint32(InputLogVersion)); err != nil {
+		return err
+	}
+	m.setInputLog(&inputLog{w: w})
+	return nil
+}
+
+// Replay replays the events recorded by Record. The machine must be set
+// up in the same way as the recorded one. Serial input from the host and
+// the nic backend are ignored while replaying, and the machine stops
+// with an ErrInputLog exception if the replay diverges. After all the
+// events are replayed, the machine takes live input again.
+func (m *Machine) Replay(r io.Reader) error {
+	events, err := readInputLog(r)
+	if err != nil {
+		return err
+	}
+	m.setInputLog(&inputLog{events: events})
+	return nil
+}
+
+// SerialIn feeds a byte from the host into the serial input buffer.
+// It returns false if the buffer is full or the machine is replaying.
+func (m *Machine) SerialIn(b byte) bool {
+	if m.input.replaying() {
+		return false
+	}
+	if !m.serial.WriteByte(b) {
+		return false
+	}
+	if m.input.recording() {
+		m.input.record(inputSerial, 0, []byte{b})
+	}
+	return true
+}
+
+// replaySerial replays the serial input of the current tick.
+func (m *Machine) replaySerial() {
+	for {
+		ev, ok := m.input.next(inputSerial)
+		if !ok {
+			return
+		}
+		if len(ev.bytes) != 1 || !m.serial.WriteByte(ev.bytes[0]) {
+			m.input.fail("replay diverged on serial input")
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package arch8
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInputLog(t *testing.T) {
+	newMachine := func(seed int64) *Machine {
+		m := NewMachine(PageSize*64, 1)
+		m.WriteWord(InitPC, (J<<30)|0x3fffffff) // j -1
+		m.RandSeed(seed)
+		m.ticker.Interval = 10
+		return m
+	}
+	run := func(m *Machine, n int) {
+		if _, e := m.Run(n); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	log := new(bytes.Buffer)
+	m := newMachine(1)
+	if err := m.Record(log); err != nil {
+		t.Fatal(err)
+	}
+	run(m, 50)
+	m.SerialIn('a')
+	m.SerialIn('b')
+	run(m, 50)
+
+	m2 := newMachine(2)
+	if err := m2.Replay(bytes.NewReader(log.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	m2.SerialIn('x') // ignored when replaying
+	run(m2, 100)
+
+	if m.ticker.nextTick != m2.ticker.nextTick {
+		t.Errorf("ticker diverged")
+	}
+	io1 := m.phyMem.Page(pageBasicIO).uints
+	io2 := m2.phyMem.Page(pageBasicIO).uints
+	for i := range io1 {
+		if io1[i] != io2[i] {
+			t.Fatalf("serial diverged at word %d", i)
+		}
+	}
+
+	m3 := newMachine(3)
+	m3.ticker.nextTick = 5 // the first time interrupt is delayed
+	if err := m3.Replay(bytes.NewReader(log.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if _, e := m3.Run(100); e == nil || !IsErr(e, ErrInputLog) {
+		t.Fatalf("expect replay to diverge, got %v", e)
+	}
+}
@@ -0,0 +1,69 @@
+package arch8
+
+import (
+	"math"
+)
+
+func isFinite(f float32) bool {
+	return !math.IsNaN(float64(f)) && !math.IsInf(float64(f), 0)
+}
+
+// instFloat executes a float instruction. A float result that is NaN or
+// infinite raises a float exception and the destination is not written,
+// so is a NaN operand in a comparison, or a float that does not fit in an
+// int when converting.
+func instFloat(funct, s1, s2 uint32) (uint32, *Excep) {
+	f1 := math.Float32frombits(s1)
+	f2 := math.Float32frombits(s2)
+	var fd float32
+
+	switch funct {
+	case FADD:
+		fd = f1 + f2
+	case FSUB:
+		fd = f1 - f2
+	case FMUL:
+		fd = f1 * f2
+	case FDIV:
+		fd = f1 / f2
+	case FSQRT:
+		fd = float32(math.Sqrt(float64(f1)))
+	case FABS:
+		fd = float32(math.Abs(float64(f1)))
+	case FNEG:
+		fd = -f1
+	case FITOF:
+		fd = float32(int32(s1))
+	case FINT: // to an unsigned int
+		f := math.Trunc(float64(f1))
+		if math.IsNaN(f) || f < 0 || f > math.MaxUint32 {
+			return 0, errFloat
+		}
+		return uint32(f), nil
+	case FTOI: // to a signed int
+		f := math.Trunc(float64(f1))
+		if math.IsNaN(f) || f < math.MinInt32 || f > math.MaxInt32 {
+			return 0, errFloat
+		}
+		return uint32(int32(f)), nil
+	case FEQ, FLT, FLE:
+		if math.IsNaN(float64(f1)) || math.IsNaN(float64(f2)) {
+			return 0, errFloat
+		}
+		switch funct {
+		case FEQ:
+			return boolWord(f1 == f2), nil
+		case FLT:
+			return boolWord(f1 < f2), nil
+		default:
+			return boolWord(f1 <= f2), nil
+		}
+	default:
+		return 0, errInvalidInst
+	}
+
+	if !isFinite(fd) {
+		return 0, errFloat
+	}
+	return math.Float32bits(fd), nil
+}
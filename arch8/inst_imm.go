@@ -50,6 +50,15 @@ func (i *instImm) I(cpu *cpu, in uint32) *Excep {
 		e = cpu.writeWord(addr, d)
 	case SB:
 		e = cpu.writeByte(addr, byte(d))
+	case LL:
+		d, e = cpu.loadLinked(addr)
+	case SC:
+		var ok bool
+		ok, e = cpu.storeCond(addr, d)
+		d = 0
+		if ok {
+			d = 1
+		}
 	default:
 		return errInvalidInst
 	}
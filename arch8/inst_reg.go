@@ -1,9 +1,5 @@
 package arch8
 
-import (
-	"math"
-)
-
 // InstReg executes register based instructions
 type instReg struct{}
 
@@ -25,6 +21,7 @@ func (i *instReg) I(cpu *cpu, in uint32) *Excep {
 	s1 := cpu.regs[src1]
 	s2 := cpu.regs[src2]
 	d := uint32(0)
+	var e *Excep
 
 	if isFloat == 0 {
 		switch funct {
@@ -94,31 +91,19 @@ func (i *instReg) I(cpu *cpu, in uint32) *Excep {
 			} else {
 				d = s1 % s2
 			}
+		case CAS:
+			d, e = cpu.compareAndSwap(s1, cpu.regs[dest], s2)
+		case XADD:
+			d, e = cpu.fetchAdd(s1, s2)
 		default:
 			return errInvalidInst
 		}
 	} else {
-		f1 := math.Float32frombits(s1)
-		f2 := math.Float32frombits(s2)
-		var fd float32
-		switch funct {
-		case FADD:
-			fd = f1 + f2
-		case FSUB:
-			fd = f1 - f2
-		case FMUL:
-			fd = f1 * f2
-		case FDIV:
-			fd = f1 / f2
-		case FINT:
-			d = uint32(f1)
-		default:
-			return errInvalidInst
-		}
+		d, e = instFloat(funct, s1, s2)
+	}
 
-		if funct != 4 {
-			d = math.Float32bits(fd)
-		}
+	if e != nil {
+		return e
 	}
 
 	cpu.regs[dest] = d
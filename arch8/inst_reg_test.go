@@ -135,6 +135,9 @@ func TestInstReg(t *testing.T) {
 			f2 := math.Float32frombits(uint32(rand.Int63()))
 
 			exp := f(f1, f2)
+			if !isFinite(exp) {
+				continue // raises float exception
+			}
 			tstf(op, s1, s2, d, f1, f2, exp)
 		}
 	}
@@ -207,3 +210,82 @@ func TestInstReg(t *testing.T) {
 	tff(FMUL, func(a, b float32) float32 { return a * b })
 	tff(FDIV, func(a, b float32) float32 { return a / b })
 }
+
+func TestInstFloat(t *testing.T) {
+	m := newPhyMemory(PageSize * 32)
+	cpu := newCPU(m, new(instReg), 0)
+
+	run := func(op, v1, v2 uint32) (uint32, *Excep) {
+		cpu.Reset()
+		in := op&0xff | 1<<18 | 2<<15 | 3<<21 | 0x1<<8
+		m.WriteWord(InitPC, in)
+		cpu.regs[1] = v1
+		cpu.regs[2] = v2
+		cpu.regs[3] = 0xdeadbeef
+		e := cpu.Tick()
+		return cpu.regs[3], e
+	}
+
+	fb := func(f float32) uint32 { return math.Float32bits(f) }
+	for _, test := range []struct {
+		op, v1, v2, res uint32
+	}{
+		{FEQ, fb(1.5), fb(1.5), 1},
+		{FEQ, fb(1.5), fb(2), 0},
+		{FLT, fb(-1), fb(2), 1},
+		{FLT, fb(2), fb(2), 0},
+		{FLE, fb(2), fb(2), 1},
+		{FLE, fb(3), fb(2), 0},
+		{FITOF, 0xfffffffd, 0, fb(-3)},
+		{FINT, fb(3.7), 0, 3},
+		{FINT, fb(3e9), 0, 3000000000},
+		{FTOI, fb(-3.7), 0, 0xfffffffd},
+		{FTOI, fb(3.7), 0, 3},
+		{FSQRT, fb(6.25), 0, fb(2.5)},
+		{FABS, fb(-6.25), 0, fb(6.25)},
+		{FNEG, fb(6.25), 0, fb(-6.25)},
+	} {
+		got, e := run(test.op, test.v1, test.v2)
+		if e != nil {
+			t.Errorf("op %d: unexpected exception: %s", test.op, e)
+		} else if got != test.res {
+			t.Errorf("op %d: got 0x%08x, expect 0x%08x",
+				test.op, got, test.res,
+			)
+		}
+	}
+
+	nan := uint32(0x7fc00000)
+	inf := fb(float32(math.Inf(1)))
+	for _, test := range []struct {
+		op, v1, v2 uint32
+	}{
+		{FDIV, fb(1), fb(0)},
+		{FDIV, fb(0), fb(0)},
+		{FMUL, fb(3e38), fb(3e38)},
+		{FADD, nan, fb(1)},
+		{FSUB, inf, fb(1)},
+		{FSQRT, fb(-1), 0},
+		{FNEG, inf, 0},
+		{FEQ, nan, nan},
+		{FLT, fb(1), nan},
+		{FINT, fb(5e9), 0},
+		{FINT, fb(-3.7), 0},
+		{FINT, nan, 0},
+		{FTOI, fb(3e9), 0},
+		{FTOI, nan, 0},
+	} {
+		got, e := run(test.op, test.v1, test.v2)
+		if e == nil || e.Code != ErrFloat {
+			t.Errorf("op %d: expect float exception, got %v",
+				test.op, e,
+			)
+		} else if got != 0xdeadbeef {
+			t.Errorf("op %d: dest written on float exception", test.op)
+		}
+	}
+
+	if _, e := run(13, 0, 0); e != errInvalidInst {
+		t.Errorf("expect invalid inst, got %v", e)
+	}
+}
@@ -34,6 +34,28 @@ func (i *instSys) I(cpu *cpu, in uint32) *Excep {
 		return cpu.Iret()
 	case CPUID:
 		s = uint32(cpu.index)
+	case IPI:
+		if cpu.UserMode() {
+			return errInvalidInst
+		}
+		return cpu.ipi(s)
+	case WFI:
+		if cpu.UserMode() {
+			return errInvalidInst
+		}
+		cpu.state = coreWaiting
+	case TLBFLUSH:
+		if cpu.UserMode() {
+			return errInvalidInst
+		}
+		cpu.virtMem.tlb.flush()
+	case TLBINV:
+		if cpu.UserMode() {
+			return errInvalidInst
+		}
+		cpu.virtMem.tlb.flushPage(s / PageSize)
+	case FENCE:
+		// cores see the memory writes in order, nothing to wait for
 	default:
 		return errInvalidInst
 	}
@@ -55,6 +55,12 @@ func (in *interrupt) Clear(i byte) {
 	in.writeByte(off, b)
 }
 
+// Pending checks if an interrupt is issued and not cleared yet.
+func (in *interrupt) Pending(i byte) bool {
+	b := in.readByte(uint32(i/8) + intPending)
+	return b&(0x1<<(i%8)) != 0
+}
+
 // Enable sets the interrupt enable bit in the flags.
 func (in *interrupt) Enable() {
 	b := in.readByte(intFlags)
@@ -91,6 +97,12 @@ func (in *interrupt) DisableInt(i byte) {
 	in.writeByte(off, b)
 }
 
+// IntEnabled tests if a particular interrupt is not masked.
+func (in *interrupt) IntEnabled(i byte) bool {
+	b := in.readByte(uint32(i/8) + intMask)
+	return b&(0x1<<(i%8)) != 0
+}
+
 // Flags returns the flags byte.
 func (in *interrupt) Flags() byte {
 	return in.readByte(intFlags)
@@ -102,7 +114,12 @@ func (in *interrupt) Poll() (bool, byte) {
 	if flag&0x1 == 0 { // interrupt is disabled
 		return false, 0
 	}
+	return in.next()
+}
 
+// next looks for the next pending interrupt that is not masked,
+// regardless of the master enabling switch.
+func (in *interrupt) next() (bool, byte) {
 	// search bits based on priorities.
 	// smaller is higher
 	for i := uint32(0); i < Ninterrupt/32; i++ {
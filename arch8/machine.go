@@ -3,7 +3,6 @@ package arch8
 import (
 	"bytes"
 	"io"
-	"math/rand"
 
 	"e8vm.io/e8vm/e8"
 )
@@ -16,9 +15,28 @@ type Machine struct {
 	serial  *serial
 	console *console
 	ticker  *ticker
+	rtc     *rtc
 	rom     *rom
+	disk    *disk
+	nic     *nic
+	debug   *debugger
+	input   *inputLog
 
-	devices []device
+	sampling *sampling
+
+	devices   []Device
+	ioRegions []*IORegion
+	ntick     uint64
+}
+
+// Close releases the host resources that the machine holds, which is the
+// image file of the attached disk. The machine should not run after it is
+// closed.
+func (m *Machine) Close() error {
+	if m.disk == nil {
+		return nil
+	}
+	return m.disk.Close()
 }
 
 // Default SP settings.
@@ -36,19 +54,24 @@ func NewMachine(memSize uint32, ncore int) *Machine {
 	ret.cores = newMultiCore(ncore, ret.phyMem, ret.inst)
 
 	// hook-up devices
-	p := ret.phyMem.Page(pageBasicIO)
+	consoleIO := ret.mustMapIO(pageBasicIO, consoleBase, consoleSize)
+	ret.mustMapIO(pageBasicIO, bootArgOffset, 4)
+	serialIO := ret.mustMapIO(pageBasicIO, serialBase, serialSize)
+	rtcIO := ret.mustMapIO(pageBasicIO, rtcBase, rtcIOSize(ncore))
 
-	ret.serial = newSerial(p, ret.cores)
-	ret.console = newConsole(p, ret.cores)
+	ret.serial = newSerial(serialIO.p, ret.cores)
+	ret.console = newConsole(consoleIO.p, ret.cores)
 	ret.ticker = newTicker(ret.cores)
+	ret.rtc = newRTC(rtcIO.p, ret.cores, ncore)
 
-	ret.addDevice(ret.ticker)
-	ret.addDevice(ret.serial)
-	ret.addDevice(ret.console)
+	ret.AddDevice(ret.ticker)
+	ret.AddDevice(ret.serial)
+	ret.AddDevice(ret.console)
+	ret.AddDevice(ret.rtc)
 
 	sys := ret.phyMem.Page(pageSysInfo)
-	sys.WriteWord(0, ret.phyMem.npage)
-	sys.WriteWord(4, uint32(ncore))
+	sys.WriteWord(sysNpage, ret.phyMem.npage)
+	sys.WriteWord(sysNcore, uint32(ncore))
 
 	ret.SetSP(DefaultSPBase, DefaultSPStride)
 
@@ -56,10 +79,12 @@ func NewMachine(memSize uint32, ncore int) *Machine {
 }
 
 // MountROM mounts the root of the read-only disk.
+// It panics if the rom is already mounted.
 func (m *Machine) MountROM(root string) {
-	p := m.phyMem.Page(pageBasicIO)
-	m.rom = newROM(p, m.phyMem, m.cores, root)
-	m.addDevice(m.rom)
+	p := m.mustMapIO(pageBasicIO, romBase, romIOSize)
+	m.rom = newROM(p.p, m.phyMem, m.cores, root)
+	m.rom.input = m.input
+	m.AddDevice(m.rom)
 }
 
 // WriteByte writes the byte at a particular physical address.
@@ -88,17 +113,31 @@ func (m *Machine) SetOutput(w io.Writer) {
 	m.console.Output = w
 }
 
-// AddDevice adds a devices to the machine.
-func (m *Machine) addDevice(d device) {
-	m.devices = append(m.devices, d)
-}
-
 // Tick proceeds the simulation by one tick.
 func (m *Machine) Tick() *CoreExcep {
+	if e := m.cores.breakpoint(); e != nil {
+		return e
+	}
+
+	if m.input.replaying() {
+		m.replaySerial()
+	}
 	for _, d := range m.devices {
 		d.Tick()
 	}
 
+	if m.sampling != nil {
+		m.sampling.tick(m.cores)
+	}
+
+	m.ntick++
+	if m.input != nil {
+		m.input.tick = m.ntick
+		if m.input.exp != nil {
+			return &CoreExcep{0, m.input.exp}
+		}
+	}
+
 	return m.cores.Tick()
 }
 
@@ -144,11 +183,15 @@ func (m *Machine) WriteBytes(r io.Reader, offset uint32) error {
 
 // RandSeed sets the random seed of the ticker.
 func (m *Machine) RandSeed(s int64) {
-	m.ticker.Rand = rand.New(rand.NewSource(s))
+	m.ticker.setSeed(s)
 }
 
 func (m *Machine) loadSections(secs []*e8.Section) error {
 	for _, s := range secs {
+		if !s.Loadable() {
+			continue
+		}
+
 		var buf io.Reader
 		if s.Type == e8.Zeros {
 			buf = &zeroReader{s.Header.Size}
@@ -186,17 +229,21 @@ func findCodeStart(secs []*e8.Section) (uint32, bool) {
 	return 0, false
 }
 
-// LoadImage loads an e8 image into the machine.
+// LoadImage loads an e8 image into the machine. The cores start at the
+// entry address of the image, or at the first code section for images
+// with no entry address.
 func (m *Machine) LoadImage(r io.ReadSeeker) error {
-	secs, err := e8.Read(r)
+	img, err := e8.ReadImage(r)
 	if err != nil {
 		return err
 	}
-	if err := m.loadSections(secs); err != nil {
+	if err := m.loadSections(img.Sections); err != nil {
 		return err
 	}
 
-	if pc, found := findCodeStart(secs); found {
+	if img.HasEntry {
+		m.SetPC(img.Entry)
+	} else if pc, found := findCodeStart(img.Sections); found {
 		m.SetPC(pc)
 	}
 	return nil
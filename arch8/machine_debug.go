@@ -0,0 +1,117 @@
+package arch8
+
+// debugger returns the debugger of the machine, and attaches one if
+// the machine does not have one yet.
+func (m *Machine) debugger() *debugger {
+	if m.debug == nil {
+		m.debug = newDebugger()
+		m.cores.setDebugger(m.debug)
+	}
+	return m.debug
+}
+
+// AddBreakpoint adds a breakpoint at a particular PC. When any core is
+// about to execute the instruction at pc, the machine stops with an
+// ErrBreakpoint exception before the tick.
+func (m *Machine) AddBreakpoint(pc uint32) {
+	m.debugger().breaks[pc] = true
+}
+
+// RemoveBreakpoint removes the breakpoint at a particular PC.
+func (m *Machine) RemoveBreakpoint(pc uint32) {
+	delete(m.debugger().breaks, pc)
+}
+
+// Breakpoints returns the PCs of all the breakpoints in order.
+func (m *Machine) Breakpoints() []uint32 {
+	return m.debugger().breakpoints()
+}
+
+// AddWatchpoint adds a memory write watchpoint. When virt is true, addr
+// is a virtual address of the core that performs the write, otherwise it
+// is a physical address. Only writes performed by the cores are watched.
+// After the writing instruction completes, the machine stops with an
+// ErrWatchpoint exception.
+func (m *Machine) AddWatchpoint(addr uint32, virt bool) {
+	m.debugger().watches[watchpoint{addr, virt}] = true
+}
+
+// RemoveWatchpoint removes a memory write watchpoint.
+func (m *Machine) RemoveWatchpoint(addr uint32, virt bool) {
+	delete(m.debugger().watches, watchpoint{addr, virt})
+}
+
+// StepCore executes one instruction on a particular core, without
+// ticking the devices or the other cores. A breakpoint at the current
+// PC of the core is ignored.
+func (m *Machine) StepCore(core int) *CoreExcep {
+	return m.cores.Step(core)
+}
+
+// Ncore returns the number of cores of the machine.
+func (m *Machine) Ncore() int {
+	return len(m.cores.cores)
+}
+
+// Reg returns the value of a register of a core.
+func (m *Machine) Reg(core, reg int) uint32 {
+	return m.cores.cores[core].regs[reg]
+}
+
+// SetReg sets the value of a register of a core.
+func (m *Machine) SetReg(core, reg int, v uint32) {
+	m.cores.cores[core].regs[reg] = v
+}
+
+// Ring returns the current ring level of a core.
+func (m *Machine) Ring(core int) byte {
+	return m.cores.cores[core].ring
+}
+
+// ReadByte reads the byte at a particular physical address.
+func (m *Machine) ReadByte(phyAddr uint32) (byte, error) {
+	b, exp := m.phyMem.ReadByte(phyAddr)
+	if exp == nil {
+		return b, nil
+	}
+	return 0, exp
+}
+
+// ReadWord reads the word at a particular physical address.
+// The address must be word aligned.
+func (m *Machine) ReadWord(phyAddr uint32) (uint32, error) {
+	w, exp := m.phyMem.ReadWord(phyAddr)
+	if exp == nil {
+		return w, nil
+	}
+	return 0, exp
+}
+
+// PhyAddr translates a virtual address into a physical address with the
+// current page table of a core, as if it is in ring 0. The use and dirty
+// bits in the page table are not changed.
+func (m *Machine) PhyAddr(core int, addr uint32) (uint32, error) {
+	pa, exp := m.cores.cores[core].virtMem.Translate(addr, 0)
+	if exp == nil {
+		return pa, nil
+	}
+	return 0, exp
+}
+
+// ReadVirtByte reads the byte at a particular virtual address of a core.
+func (m *Machine) ReadVirtByte(core int, addr uint32) (byte, error) {
+	pa, err := m.PhyAddr(core, addr)
+	if err != nil {
+		return 0, err
+	}
+	return m.ReadByte(pa)
+}
+
+// ReadVirtWord reads the word at a particular virtual address of a core.
+func (m *Machine) ReadVirtWord(core int, addr uint32) (uint32, error) {
+	pa, err := m.PhyAddr(core, addr)
+	if err != nil {
+		return 0, err
+	}
+	return m.ReadWord(pa)
+}
@@ -8,6 +8,13 @@ import (
 type multiCore struct {
 	cores  []*cpu
 	phyMem *phyMemory
+
+	debug *debugger
+
+	coreHalt bool // halt only the core that executes halt
+
+	sched Scheduler // nil for in order
+	order []int
 }
 
 // NewMultiCore creates a shared memory multicore processor.
@@ -20,8 +27,12 @@ func newMultiCore(ncore int, mem *phyMemory, i inst) *multiCore {
 	ret.cores = make([]*cpu, ncore)
 	ret.phyMem = mem
 
+	resv := new(reservations)
 	for ind := range ret.cores {
-		ret.cores[ind] = newCPU(mem, i, byte(ind))
+		c := newCPU(mem, i, byte(ind))
+		c.virtMem.resv = resv
+		c.bus = ret
+		ret.cores[ind] = c
 	}
 
 	return ret
@@ -35,16 +46,76 @@ type CoreExcep struct {
 
 // Tick performs one tick on each core.
 func (c *multiCore) Tick() *CoreExcep {
-	for i, core := range c.cores {
-		e := core.Tick()
+	if c.coreHalt && c.allHalted() {
+		return &CoreExcep{0, errHalt}
+	}
+
+	if c.sched == nil {
+		c.order = inOrder(len(c.cores), c.order[:0])
+	} else {
+		c.order = c.sched.Schedule(len(c.cores), c.order[:0])
+	}
+
+	for _, i := range c.order {
+		e := c.tickCore(c.cores[i])
 		if e != nil {
 			return &CoreExcep{i, e}
 		}
 	}
 
+	if c.debug != nil {
+		c.debug.passed()
+	}
+	return nil
+}
+
+// Step performs one tick on a particular core only.
+func (c *multiCore) Step(core int) *CoreExcep {
+	e := c.tickCore(c.cores[core])
+	if c.debug != nil {
+		delete(c.debug.resume, core)
+	}
+	if e != nil {
+		return &CoreExcep{core, e}
+	}
 	return nil
 }
 
+func (c *multiCore) tickCore(core *cpu) *Excep {
+	e := core.Tick()
+	if e != nil && e.Code == ErrHalt && c.coreHalt {
+		core.halt()
+		if !c.allHalted() {
+			e = nil
+		}
+	}
+	if c.debug == nil {
+		return e
+	}
+
+	// always take the hit, so that it does not leak to the next tick
+	if hit := c.debug.takeHit(); e == nil {
+		return hit
+	}
+	return e
+}
+
+// breakpoint checks if any core hits a breakpoint.
+func (c *multiCore) breakpoint() *CoreExcep {
+	if c.debug == nil {
+		return nil
+	}
+	return c.debug.checkBreaks(c.cores)
+}
+
+// setDebugger attaches a debugger to all the cores.
+func (c *multiCore) setDebugger(d *debugger) {
+	c.debug = d
+	for _, core := range c.cores {
+		core.virtMem.debug = d
+	}
+}
+
 // Ncore returns the number of cores.
 func (c *multiCore) Ncore() byte {
 	return byte(len(c.cores))
@@ -87,4 +158,15 @@ func printCPUStatus(c *cpu) {
 	p("pc", PC)
 
 	fmt.Printf("ring = %d\n", c.ring)
+
+	if p := c.perf.counters(); p.Cycles > 0 {
+		fmt.Printf("perf: %d cycles, %d retired, %d faults, %d ints\n",
+			p.Cycles, p.Retired, p.PageFaults, p.Interrupts,
+		)
+	}
+	if st := c.virtMem.tlb.stats; st.Hits+st.Misses > 0 {
+		fmt.Printf("tlb: %d hits, %d misses, %d flushes\n",
+			st.Hits, st.Misses, st.Flushes,
+		)
+	}
 }
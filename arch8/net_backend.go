@@ -0,0 +1,106 @@
+package arch8
+
+import (
+	"encoding/binary"
+	"io"
+	"log"
+)
+
+// NetBackend carries the packets of a nic on the host.
+type NetBackend interface {
+	// Send sends a packet out of the machine.
+	Send(p []byte) error
+
+	// Recv returns the next packet for the machine, or nil if there is
+	// none yet. It must not block.
+	Recv() []byte
+}
+
+// netQueueSize is the number of packets that a backend buffers.
+const netQueueSize = 64
+
+// netPair is one end of an in-process link.
+type netPair struct {
+	in  <-chan []byte
+	out chan<- []byte
+}
+
+// NewNetPair creates two backends linked with each other in the
+// process, for connecting the nics of two machines. Packets are dropped
+// when the peer does not receive fast enough.
+func NewNetPair() (NetBackend, NetBackend) {
+	a := make(chan []byte, netQueueSize)
+	b := make(chan []byte, netQueueSize)
+	return &netPair{in: a, out: b}, &netPair{in: b, out: a}
+}
+
+func (p *netPair) Send(bs []byte) error {
+	select {
+	case p.out <- append([]byte(nil), bs...):
+	default:
+	}
+	return nil
+}
+
+func (p *netPair) Recv() []byte {
+	select {
+	case bs := <-p.in:
+		return bs
+	default:
+		return nil
+	}
+}
+
+// netStream frames packets on a byte stream, each packet prefixed with
+// its length as a little endian uint32.
+type netStream struct {
+	w  io.Writer
+	in chan []byte
+}
+
+// NewNetStream creates a backend that reads packets from r and writes
+// packets into w, such as a pair of pipes or a unix socket.
+func NewNetStream(r io.Reader, w io.Writer) NetBackend {
+	ret := &netStream{w: w, in: make(chan []byte, netQueueSize)}
+	go ret.readLoop(r)
+	return ret
+}
+
+func (s *netStream) readLoop(r io.Reader) {
+	for {
+		var n uint32
+		if err := binary.Read(r, Endian, &n); err != nil {
+			if err != io.EOF {
+				log.Println(err)
+			}
+			return
+		}
+		if n > NicMTU {
+			log.Printf("packet of %d bytes is too long", n)
+			return
+		}
+		bs := make([]byte, n)
+		if _, err := io.ReadFull(r, bs); err != nil {
+			log.Println(err)
+			return
+		}
+		s.in <- bs
+	}
+}
+
+func (s *netStream) Send(bs []byte) error {
+	buf := make([]byte, 4+len(bs))
+	Endian.PutUint32(buf, uint32(len(bs)))
+	copy(buf[4:], bs)
+	_, err := s.w.Write(buf)
+	return err
+}
+
+func (s *netStream) Recv() []byte {
+	select {
+	case bs := <-s.in:
+		return bs
+	default:
+		return nil
+	}
+}
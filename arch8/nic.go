@@ -0,0 +1,194 @@
+package arch8
+
+// nic registers
+const (
+	nicCtrl = 0 // 1 to enable the nic, set by cpu
+
+	nicTxRing = 4  // physical address of the tx ring
+	nicTxSize = 8  // number of descriptors in the tx ring
+	nicTxHead = 12 // index of the next packet to send, set by cpu
+	nicTxTail = 16 // index of the next packet to be sent, set by hardware
+
+	nicRxRing = 20 // physical address of the rx ring
+	nicRxSize = 24 // number of descriptors in the rx ring
+	nicRxHead = 28 // index of the next buffer to fill, set by hardware
+	nicRxTail = 32 // index of the next buffer to be freed, set by cpu
+
+	nicDropped = 36 // number of bad tx descriptors, set by hardware
+
+	nicBase   = 0x1a0
+	nicIOSize = 40
+)
+
+// NicMTU is the max size of a packet, and the size of each rx buffer.
+const NicMTU = 1536
+
+// nicDescSize is the size of a ring descriptor: the physical address of
+// the packet buffer, and then the length of the packet.
+const nicDescSize = 8
+
+// nic is a network interface that moves packets between the rings in
+// the physical memory and a host backend. The ring indices count up and
+// wrap around; a descriptor is used when its index is between the tail
+// and the head. An interrupt is raised when a packet is sent or
+// received.
+type nic struct {
+	intBus  intBus
+	p       *pageOffset
+	mem     *phyMemory
+	backend NetBackend
+	input   *inputLog
+
+	Core byte
+	Int  byte
+}
+
+func newNIC(p *pageOffset, mem *phyMemory, i intBus, b NetBackend) *nic {
+	return &nic{
+		intBus:  i,
+		p:       p,
+		mem:     mem,
+		backend: b,
+		Int:     IntNIC,
+	}
+}
+
+func (n *nic) interrupt() {
+	n.intBus.Interrupt(n.Int, n.Core)
+}
+
+// desc returns the address of the descriptor of index in a ring.
+func (n *nic) desc(ring, size, index uint32) uint32 {
+	return ring + (index%size)*nicDescSize
+}
+
+func (n *nic) readMem(addr, size uint32) ([]byte, bool) {
+	ret := make([]byte, size)
+	for i := range ret {
+		b, e := n.mem.ReadByte(addr + uint32(i))
+		if e != nil {
+			return nil, false
+		}
+		ret[i] = b
+	}
+	return ret, true
+}
+
+func (n *nic) writeMem(addr uint32, bs []byte) bool {
+	for i, b := range bs {
+		if e := n.mem.WriteByte(addr+uint32(i), b); e != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *nic) readDesc(addr uint32) (uint32, uint32, bool) {
+	bs, ok := n.readMem(addr, nicDescSize)
+	if !ok {
+		return 0, 0, false
+	}
+	return Endian.Uint32(bs[:4]), Endian.Uint32(bs[4:]), true
+}
+
+// send sends at most one packet from the tx ring.
+func (n *nic) send() {
+	size := n.p.readWord(nicTxSize)
+	head := n.p.readWord(nicTxHead)
+	tail := n.p.readWord(nicTxTail)
+	if size == 0 || head == tail {
+		return
+	}
+
+	d := n.desc(n.p.readWord(nicTxRing), size, tail)
+	addr, length, ok := n.readDesc(d)
+	var bs []byte
+	if ok && length <= NicMTU {
+		bs, ok = n.readMem(addr, length)
+	} else {
+		ok = false
+	}
+	if ok && n.backend != nil && !n.input.replaying() {
+		ok = n.backend.Send(bs) == nil
+	}
+	if !ok {
+		n.p.writeWord(nicDropped, n.p.readWord(nicDropped)+1)
+	}
+
+	n.p.writeWord(nicTxTail, tail+1)
+	n.interrupt()
+}
+
+// recv takes the next packet from the backend, or from the input log
+// when replaying.
+func (n *nic) recv() []byte {
+	if n.input.replaying() {
+		if ev, ok := n.input.next(inputNIC); ok {
+			return ev.bytes
+		}
+		return nil
+	}
+	if n.backend == nil {
+		return nil
+	}
+
+	bs := n.backend.Recv()
+	if bs != nil && n.input.recording() {
+		n.input.record(inputNIC, 0, bs)
+	}
+	return bs
+}
+
+// receive receives at most one packet into the rx ring.
+func (n *nic) receive() {
+	size := n.p.readWord(nicRxSize)
+	head := n.p.readWord(nicRxHead)
+	tail := n.p.readWord(nicRxTail)
+	if size == 0 || head-tail >= size {
+		return // ring full
+	}
+
+	bs := n.recv()
+	if bs == nil {
+		return
+	}
+	if len(bs) > NicMTU {
+		bs = bs[:NicMTU]
+	}
+
+	d := n.desc(n.p.readWord(nicRxRing), size, head)
+	addr, _, ok := n.readDesc(d)
+	if !ok || !n.writeMem(addr, bs) {
+		return // bad descriptor, packet lost
+	}
+	var lenBuf [4]byte
+	Endian.PutUint32(lenBuf[:], uint32(len(bs)))
+	if !n.writeMem(d+4, lenBuf[:]) {
+		return
+	}
+
+	n.p.writeWord(nicRxHead, head+1)
+	n.interrupt()
+}
+
+func (n *nic) Tick() {
+	if n.p.readByte(nicCtrl)&0x1 == 0 {
+		return
+	}
+	n.send()
+	n.receive()
+}
+
+// AttachNIC attaches a network interface that sends and receives the
+// packets with backend. Only one nic can be attached. The nic is not
+// saved in snapshots, and needs to be attached again after restoring.
+func (m *Machine) AttachNIC(backend NetBackend) error {
+	p, err := m.MapIO(pageBasicIO, nicBase, nicIOSize)
+	if err != nil {
+		return err
+	}
+	m.nic = newNIC(p.p, m.phyMem, m.cores, backend)
+	m.nic.input = m.input
+	m.AddDevice(m.nic)
+	return nil
+}
@@ -0,0 +1,106 @@
+package arch8
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+const (
+	testTxRing = 0x9000
+	testRxRing = 0x9100
+	testBuf    = 0xa000
+)
+
+// nicSetup enables the nic with rings of 4 descriptors.
+func nicSetup(t *testing.T, m *Machine, b NetBackend) {
+	if err := m.AttachNIC(b); err != nil {
+		t.Fatal(err)
+	}
+	const base = pageBasicIO*PageSize + nicBase
+	m.WriteWord(base+nicTxRing, testTxRing)
+	m.WriteWord(base+nicTxSize, 4)
+	m.WriteWord(base+nicRxRing, testRxRing)
+	m.WriteWord(base+nicRxSize, 4)
+	for i := uint32(0); i < 4; i++ {
+		m.WriteWord(testRxRing+i*nicDescSize, testBuf+i*NicMTU)
+	}
+	m.WriteByte(base+nicCtrl, 1)
+}
+
+func nicSend(m *Machine, bs []byte) {
+	const base = pageBasicIO*PageSize + nicBase
+	for i, b := range bs {
+		m.WriteByte(0xb000+uint32(i), b)
+	}
+	head, _ := m.ReadWord(base + nicTxHead)
+	d := testTxRing + (head%4)*nicDescSize
+	m.WriteWord(d, 0xb000)
+	m.WriteWord(d+4, uint32(len(bs)))
+	m.WriteWord(base+nicTxHead, head+1)
+}
+
+func nicReceived(m *Machine, index uint32) []byte {
+	n, _ := m.ReadWord(testRxRing + index*nicDescSize + 4)
+	ret := make([]byte, n)
+	for i := range ret {
+		ret[i], _ = m.ReadByte(testBuf + index*NicMTU + uint32(i))
+	}
+	return ret
+}
+
+func TestNICPair(t *testing.T) {
+	a, b := NewNetPair()
+	m1 := NewMachine(PageSize*64, 1)
+	m2 := NewMachine(PageSize*64, 1)
+	nicSetup(t, m1, a)
+	nicSetup(t, m2, b)
+
+	nicSend(m1, []byte("hello"))
+	m1.Tick()
+	m2.Tick()
+
+	head, _ := m2.ReadWord(pageBasicIO*PageSize + nicBase + nicRxHead)
+	if head != 1 {
+		t.Fatalf("got rx head %d, want 1", head)
+	}
+	if got := nicReceived(m2, 0); string(got) != "hello" {
+		t.Fatalf("got packet %q", got)
+	}
+
+	in := m2.cores.cores[0].interrupt
+	in.Enable()
+	in.EnableInt(IntNIC)
+	if poll, code := in.Poll(); !poll || code != IntNIC {
+		t.Fatal("nic interrupt not issued")
+	}
+}
+
+func TestNICStream(t *testing.T) {
+	inR, inW := io.Pipe()
+	out := new(bytes.Buffer)
+	m := NewMachine(PageSize*64, 1)
+	nicSetup(t, m, NewNetStream(inR, out))
+
+	nicSend(m, []byte("ping"))
+	m.Tick()
+	want := []byte{4, 0, 0, 0, 'p', 'i', 'n', 'g'}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Fatalf("got stream %v", out.Bytes())
+	}
+
+	inW.Write([]byte{4, 0, 0, 0, 'p', 'o', 'n', 'g'})
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		m.Tick()
+		if got := nicReceived(m, 0); len(got) > 0 {
+			if string(got) != "pong" {
+				t.Fatalf("got packet %q", got)
+			}
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("packet not received")
+}
@@ -14,6 +14,9 @@ const (
 	LBU = 9
 	SW  = 10
 	SB  = 11
+
+	LL = 12
+	SC = 13
 )
 
 // reg instructions
@@ -39,12 +42,22 @@ const (
 	DIVU  = 18
 	MOD   = 19
 	MODU  = 20
+	CAS   = 21
+	XADD  = 22
 
-	FADD = 0
-	FSUB = 1
-	FMUL = 2
-	FDIV = 3
-	FINT = 4
+	FADD  = 0
+	FSUB  = 1
+	FMUL  = 2
+	FDIV  = 3
+	FINT  = 4
+	FEQ   = 5
+	FLT   = 6
+	FLE   = 7
+	FITOF = 8
+	FSQRT = 9
+	FABS  = 10
+	FNEG  = 11
+	FTOI  = 12
 )
 
 // branch instructions
@@ -61,6 +74,12 @@ const (
 	VTABLE  = 67
 	IRET    = 68
 	CPUID   = 69
+	FENCE   = 70
+	IPI     = 71
+	WFI     = 72
+
+	TLBFLUSH = 73
+	TLBINV   = 74
 )
 
 // jump instructions
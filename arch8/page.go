@@ -6,6 +6,7 @@ const PageSize = 4096
 // Page is a memory addressable area of PageSize bytes
 type page struct {
 	uints []uint32
+	code  *codePage // the pre-decoded instructions, nil if none
 }
 
 // NewPage creates a new empty page.
@@ -35,6 +36,9 @@ func (p *page) WriteByte(offset uint32, b byte) {
 	u &= ^(uint32(0xff) << shift)
 	u |= uint32(b) << shift
 	p.uints[pos] = u
+	if p.code != nil {
+		p.code.ops[pos].exec = nil
+	}
 }
 
 // ReadWord reads the word at the particular offset.
@@ -48,7 +52,11 @@ func (p *page) ReadWord(offset uint32) uint32 {
 // When offset is larger than offset, it uses the modular.
 // When offset is not 4-byte aligned, it aligns down.
 func (p *page) WriteWord(offset uint32, w uint32) {
-	p.uints[(offset%PageSize)/4] = w
+	pos := (offset % PageSize) / 4
+	p.uints[pos] = w
+	if p.code != nil {
+		p.code.ops[pos].exec = nil
+	}
 }
 
 // WriteAt writes a series of bytes starting at offset
@@ -61,3 +69,21 @@ func (p *page) WriteAt(bs []byte, offset uint32) {
 		p.WriteByte(off, b)
 	}
 }
+
+// isZero checks if the page is all zeros.
+func (p *page) isZero() bool {
+	for _, u := range p.uints {
+		if u != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// clear fills the page with zeros.
+func (p *page) clear() {
+	for i := range p.uints {
+		p.uints[i] = 0
+	}
+	p.code = nil
+}
@@ -20,3 +20,14 @@ func (p *pageOffset) writeWord(offset uint32, w uint32) {
 func (p *pageOffset) readWord(offset uint32) uint32 {
 	return p.page.ReadWord(p.offset + offset)
 }
+
+func (p *pageOffset) readUint64(offset uint32) uint64 {
+	lo := p.readWord(offset)
+	hi := p.readWord(offset + 4)
+	return uint64(hi)<<32 | uint64(lo)
+}
+
+func (p *pageOffset) writeUint64(offset uint32, v uint64) {
+	p.writeWord(offset, uint32(v))
+	p.writeWord(offset+4, uint32(v>>32))
+}
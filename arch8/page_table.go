@@ -28,6 +28,8 @@ func newPageTable(m *phyMemory, addr uint32) *pageTable {
 
 // bit [31:12] -> a page pointer
 //
+// bit 5: no execute bit
+// bit 4: user bit
 // bit 3: dirty bit
 // bit 2: use bit
 // bit 1: readonly bit
@@ -40,6 +42,7 @@ const (
 	pteUse      = 2
 	pteDirty    = 3
 	pteUser     = 4
+	pteNoExec   = 5
 )
 
 const u32one uint32 = 0x1
@@ -105,6 +108,11 @@ func (pt *pageTable) Translate(addr uint32, ring byte) (uint32, *Excep) {
 	return ppn*PageSize + off, nil
 }
 
+// exec checks if the last translation is executable.
+func (pt *pageTable) exec() bool {
+	return !pt.pte1.testBit(pteNoExec) && !pt.pte2.testBit(pteNoExec)
+}
+
 func (pt *pageTable) updatePte() *Excep {
 	e := pt.mem.WriteWord(pt.pte1Addr, uint32(pt.pte1))
 	if e != nil {
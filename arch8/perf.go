@@ -0,0 +1,66 @@
+package arch8
+
+// sysinfo page layout
+const (
+	sysNpage = 0 // number of physical pages
+	sysNcore = 4 // number of cores
+
+	sysPerf = 64 // the performance counters, one set for each core
+)
+
+// performance counters, 64-bit each, relative to the counters of a core
+const (
+	perfCycles  = 0  // ticks of the core, including idling and stalls
+	perfRetired = 8  // instructions retired
+	perfFaults  = 16 // page faults
+	perfInts    = 24 // interrupts and exceptions entered
+
+	perfSize = 32
+)
+
+// PerfCounters is the performance counters of a core.
+type PerfCounters struct {
+	Cycles     uint64
+	Retired    uint64
+	PageFaults uint64
+	Interrupts uint64
+}
+
+// perf keeps the performance counters of a core in the sysinfo page, so
+// that the guest can read them, and also reset them by writing zeros.
+type perf struct {
+	p *pageOffset
+}
+
+func newPerf(mem *phyMemory, core byte) *perf {
+	p := mem.Page(pageSysInfo)
+	if p == nil {
+		return nil
+	}
+	offset := sysPerf + uint32(core)*perfSize
+	return &perf{&pageOffset{p, offset}}
+}
+
+func (p *perf) inc(counter uint32) {
+	if p == nil {
+		return
+	}
+	p.p.writeUint64(counter, p.p.readUint64(counter)+1)
+}
+
+func (p *perf) counters() PerfCounters {
+	if p == nil {
+		return PerfCounters{}
+	}
+	return PerfCounters{
+		Cycles:     p.p.readUint64(perfCycles),
+		Retired:    p.p.readUint64(perfRetired),
+		PageFaults: p.p.readUint64(perfFaults),
+		Interrupts: p.p.readUint64(perfInts),
+	}
+}
+
+// PerfCounters returns the performance counters of a core.
+func (m *Machine) PerfCounters(core int) PerfCounters {
+	return m.cores.cores[core].perf.counters()
+}
@@ -40,6 +40,12 @@ func (pm *phyMemory) Size() uint32 {
 	return pm.npage * PageSize
 }
 
+// size64 returns the size of the physical memory, which does not wrap to
+// 0 for a full 4GB memory like Size does.
+func (pm *phyMemory) size64() uint64 {
+	return uint64(pm.npage) * PageSize
+}
+
 // Page returns the page for the particular page number
 // Returns nil when the page number is out of range
 func (pm *phyMemory) Page(pn uint32) *page {
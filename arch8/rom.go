@@ -20,6 +20,9 @@ const (
 
 	romFilename    = 20
 	romFilenameMax = 100
+
+	romBase   = 0x100
+	romIOSize = romFilename + romFilenameMax
 )
 
 const (
@@ -52,14 +55,16 @@ type rom struct {
 	bs        []byte // bytes read
 	err       byte
 
+	input *inputLog
+
 	Core    byte
 	IntDone byte
 }
 
-func newROM(p *page, mem *phyMemory, i intBus, root string) *rom {
+func newROM(p *pageOffset, mem *phyMemory, i intBus, root string) *rom {
 	return &rom{
 		intBus: i,
-		p:      &pageOffset{p, 0x100},
+		p:      p,
 		mem:    mem,
 		root:   root,
 
@@ -114,6 +119,31 @@ func (r *rom) readFile() (byte, error) {
 	return 0, nil
 }
 
+// read reads the file requested, or replays the read from the input log.
+func (r *rom) read() (byte, error) {
+	if r.input.replaying() {
+		ev, ok := r.input.expect(inputROM)
+		if !ok {
+			return romErrRead, nil
+		}
+		if ev.arg == romErrNone {
+			r.addr = r.p.readWord(romAddr)
+			r.bs = ev.bytes
+		}
+		return byte(ev.arg), nil
+	}
+
+	errCode, err := r.readFile()
+	if r.input.recording() {
+		var bs []byte
+		if errCode == romErrNone {
+			bs = r.bs
+		}
+		r.input.record(inputROM, uint32(errCode), bs)
+	}
+	return errCode, err
+}
+
 func (r *rom) Tick() {
 	switch r.state {
 	case romStateIdle:
@@ -121,7 +151,7 @@ func (r *rom) Tick() {
 		if cmd != 0 {
 			r.state = romStateBusy
 
-			errCode, err := r.readFile()
+			errCode, err := r.read()
 			if err != nil && err != io.EOF {
 				log.Println(err)
 			}
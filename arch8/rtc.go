@@ -0,0 +1,115 @@
+package arch8
+
+import (
+	"time"
+)
+
+// rtc registers
+const (
+	rtcLatch = 0  // set to 1 by cpu to latch the wall clock
+	rtcTicks = 8  // 64-bit count of ticks since the machine started
+	rtcTime  = 16 // 64-bit unix time in nanoseconds, when last latched
+
+	rtcTimers = 32 // the timers, one for each core
+
+	rtcBase = 0x200
+)
+
+// timer registers, relative to the timer of a core
+const (
+	timerCtrl   = 0 // bit 0 for enabled, bit 1 for periodic
+	timerCode   = 1 // the interrupt code to raise
+	timerCount  = 4 // ticks left before the timer fires
+	timerPeriod = 8 // the count to reload for periodic timers
+
+	timerSize = 16
+)
+
+const (
+	timerEnabled  = 0x1
+	timerPeriodic = 0x2
+)
+
+// rtc is a device that counts the ticks, reads the wall clock, and has
+// a programmable one-shot or periodic timer for each core. All its
+// states are kept in the io page.
+type rtc struct {
+	intBus intBus
+	p      *pageOffset
+	ncore  int
+	input  *inputLog
+
+	clock func() time.Time // the wall clock, replaceable for testing
+}
+
+func rtcIOSize(ncore int) uint32 {
+	return rtcTimers + uint32(ncore)*timerSize
+}
+
+func newRTC(p *pageOffset, i intBus, ncore int) *rtc {
+	ret := &rtc{
+		intBus: i,
+		p:      p,
+		ncore:  ncore,
+		clock:  time.Now,
+	}
+	for i := 0; i < ncore; i++ {
+		p.writeByte(rtcTimers+uint32(i)*timerSize+timerCode, IntTimer)
+	}
+	return ret
+}
+
+// now reads the wall clock, or replays it from the input log.
+func (r *rtc) now() uint64 {
+	if r.input.replaying() {
+		ev, ok := r.input.expect(inputClock)
+		if !ok || len(ev.bytes) != 8 {
+			return 0
+		}
+		return Endian.Uint64(ev.bytes)
+	}
+
+	t := uint64(r.clock().UnixNano())
+	if r.input.recording() {
+		var bs [8]byte
+		Endian.PutUint64(bs[:], t)
+		r.input.record(inputClock, 0, bs[:])
+	}
+	return t
+}
+
+func (r *rtc) tickTimer(core int) {
+	base := rtcTimers + uint32(core)*timerSize
+	ctrl := r.p.readByte(base + timerCtrl)
+	if ctrl&timerEnabled == 0 {
+		return
+	}
+
+	count := r.p.readWord(base + timerCount)
+	if count > 0 {
+		count--
+	}
+	if count == 0 {
+		r.intBus.Interrupt(r.p.readByte(base+timerCode), byte(core))
+		period := r.p.readWord(base + timerPeriod)
+		if ctrl&timerPeriodic != 0 && period > 0 {
+			count = period
+		} else {
+			r.p.writeByte(base+timerCtrl, ctrl&^timerEnabled)
+		}
+	}
+	r.p.writeWord(base+timerCount, count)
+}
+
+func (r *rtc) Tick() {
+	r.p.writeUint64(rtcTicks, r.p.readUint64(rtcTicks)+1)
+
+	if r.p.readByte(rtcLatch) != 0 {
+		r.p.writeUint64(rtcTime, r.now())
+		r.p.writeByte(rtcLatch, 0)
+	}
+
+	for i := 0; i < r.ncore; i++ {
+		r.tickTimer(i)
+	}
+}
@@ -0,0 +1,54 @@
+package arch8
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRTC(t *testing.T) {
+	m := NewMachine(PageSize*64, 2)
+	m.rtc.clock = func() time.Time { return time.Unix(3, 5) }
+
+	const base = pageBasicIO*PageSize + rtcBase
+	const timer1 = base + rtcTimers + timerSize
+	m.WriteWord(timer1+timerCount, 3)
+	m.WriteWord(timer1+timerPeriod, 2)
+	m.WriteByte(timer1+timerCtrl, timerEnabled|timerPeriodic)
+	m.WriteByte(base+rtcLatch, 1)
+
+	in := m.cores.cores[1].interrupt
+	in.Enable()
+	in.EnableInt(IntTimer)
+
+	var fired []int
+	for i := 1; i <= 7; i++ {
+		m.Tick()
+		if poll, code := in.Poll(); poll && code == IntTimer {
+			fired = append(fired, i)
+			in.Clear(IntTimer)
+		}
+	}
+	if len(fired) != 3 || fired[0] != 3 || fired[1] != 5 || fired[2] != 7 {
+		t.Fatalf("timer fired at ticks %v", fired)
+	}
+
+	if n, _ := m.ReadWord(base + rtcTicks); n != 7 {
+		t.Fatalf("got %d ticks, want 7", n)
+	}
+	lo, _ := m.ReadWord(base + rtcTime)
+	hi, _ := m.ReadWord(base + rtcTime + 4)
+	if uint64(hi)<<32|uint64(lo) != 3e9+5 {
+		t.Fatal("wrong wall clock latched")
+	}
+	if b, _ := m.ReadByte(base + rtcLatch); b != 0 {
+		t.Fatal("latch not cleared")
+	}
+
+	// one-shot
+	m.WriteByte(timer1+timerCtrl, timerEnabled)
+	m.WriteWord(timer1+timerCount, 1)
+	m.Tick()
+	if ctrl, _ := m.ReadByte(timer1 + timerCtrl); ctrl != 0 {
+		t.Fatal("one-shot timer not disabled after firing")
+	}
+}
@@ -14,6 +14,7 @@ func RunImageFile(path string) error {
 	}
 
 	m := NewMachine(0, 1)
+	m.SetFast(true)
 	if err := m.LoadImage(f); err != nil {
 		return err
 	}
@@ -26,6 +27,7 @@ func RunImageFile(path string) error {
 
 func runImageArg(bs []byte, arg uint32, n int) (int, error) {
 	m := NewMachine(0, 1)
+	m.SetFast(true)
 	if err := m.LoadImageBytes(bs); err != nil {
 		return 0, err
 	}
@@ -58,6 +60,7 @@ func RunImageArg(bs []byte, arg uint32) (int, error) {
 // the output.
 func RunImageOutput(bs []byte, n int) (int, string, error) {
 	m := NewMachine(0, 1)
+	m.SetFast(true)
 	if err := m.LoadImageBytes(bs); err != nil {
 		return 0, "", err
 	}
@@ -0,0 +1,39 @@
+package arch8
+
+// Sampler receives the periodical samples of the cores' states.
+type Sampler interface {
+	// Sample samples the state of a core. It is called before the
+	// core executes the instruction at pc.
+	Sample(core int, pc uint32, ring byte)
+}
+
+type sampling struct {
+	sampler Sampler
+	period  int
+	count   int
+}
+
+func (s *sampling) tick(c *multiCore) {
+	s.count++
+	if s.count < s.period {
+		return
+	}
+	s.count = 0
+
+	for i, core := range c.cores {
+		s.sampler.Sample(i, core.regs[PC], core.ring)
+	}
+}
+
+// SetSampler sets the sampler that samples all the cores once every
+// period ticks. A nil sampler stops the sampling.
+func (m *Machine) SetSampler(s Sampler, period int) {
+	if s == nil {
+		m.sampling = nil
+		return
+	}
+	if period <= 0 {
+		panic("invalid sampling period")
+	}
+	m.sampling = &sampling{sampler: s, period: period}
+}
@@ -0,0 +1,151 @@
+package arch8
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Scheduler decides how the cores interleave in a tick.
+type Scheduler interface {
+	// Schedule appends the indices of the cores to execute an
+	// instruction in this tick to buf in order, and returns the
+	// result. A core can execute more than once, or not at all.
+	Schedule(ncore int, buf []int) []int
+}
+
+// inOrder executes each core once in index order, which is the default.
+func inOrder(ncore int, buf []int) []int {
+	for i := 0; i < ncore; i++ {
+		buf = append(buf, i)
+	}
+	return buf
+}
+
+// RoundRobin executes each core once per tick, and rotates the core that
+// goes first on every tick.
+type RoundRobin struct {
+	first int
+}
+
+// Schedule implements Scheduler.
+func (s *RoundRobin) Schedule(ncore int, buf []int) []int {
+	s.first %= ncore
+	for i := 0; i < ncore; i++ {
+		buf = append(buf, (s.first+i)%ncore)
+	}
+	s.first++
+	return buf
+}
+
+// RandomScheduler executes each core once per tick in a random order.
+type RandomScheduler struct {
+	r *rand.Rand
+}
+
+// NewRandomScheduler creates a random scheduler with a seed.
+func NewRandomScheduler(seed int64) *RandomScheduler {
+	return &RandomScheduler{rand.New(rand.NewSource(seed))}
+}
+
+// Schedule implements Scheduler.
+func (s *RandomScheduler) Schedule(ncore int, buf []int) []int {
+	for _, i := range s.r.Perm(ncore) {
+		buf = append(buf, i)
+	}
+	return buf
+}
+
+// WeightedScheduler executes each core a number of times per tick as its
+// speed. A core of weight 0 is paused. The instructions of the cores are
+// interleaved: the cores take turns to execute one instruction each.
+type WeightedScheduler struct {
+	weights []int
+}
+
+// NewWeightedScheduler creates a weighted scheduler. Cores that are not
+// listed in weights have weight 1.
+func NewWeightedScheduler(weights []int) *WeightedScheduler {
+	return &WeightedScheduler{weights}
+}
+
+func (s *WeightedScheduler) weight(core int) int {
+	if core < len(s.weights) {
+		return s.weights[core]
+	}
+	return 1
+}
+
+// Schedule implements Scheduler.
+func (s *WeightedScheduler) Schedule(ncore int, buf []int) []int {
+	for turn := 0; ; turn++ {
+		n := len(buf)
+		for i := 0; i < ncore; i++ {
+			if turn < s.weight(i) {
+				buf = append(buf, i)
+			}
+		}
+		if len(buf) == n {
+			return buf
+		}
+	}
+}
+
+// ScriptScheduler executes the cores one at a time in a given sequence,
+// one core for each tick. After the sequence, it executes each core once
+// per tick in index order.
+type ScriptScheduler struct {
+	Seq []int
+	pos int
+}
+
+// Schedule implements Scheduler.
+func (s *ScriptScheduler) Schedule(ncore int, buf []int) []int {
+	if s.pos < len(s.Seq) {
+		s.pos++
+		return append(buf, s.Seq[s.pos-1]%ncore)
+	}
+	return inOrder(ncore, buf)
+}
+
+// SetScheduler sets the interleaving policy of the cores. A nil
+// scheduler executes each core once per tick in index order. When a core
+// executes several instructions in a tick, breakpoints are only checked
+// before the first one. The scheduler is not saved in snapshots.
+func (m *Machine) SetScheduler(s Scheduler) {
+	m.cores.sched = s
+}
+
+// Explore runs a program under all the interleavings of the first bound
+// ticks, where exactly one core executes in each tick, and then runs for
+// nticks more in index order. For each run, newMachine creates the
+// machine with the program loaded, and check checks the result. Explore
+// returns the first error from check, with the interleaving that leads
+// to it.
+func Explore(
+	newMachine func() *Machine, bound, nticks int,
+	check func(m *Machine, e *CoreExcep) error,
+) error {
+	m := newMachine()
+	ncore := len(m.cores.cores)
+	seq := make([]int, bound)
+
+	for {
+		m.SetScheduler(&ScriptScheduler{Seq: seq})
+		_, e := m.Run(bound + nticks)
+		if err := check(m, e); err != nil {
+			return fmt.Errorf("interleaving %v: %s", seq, err)
+		}
+
+		// next sequence
+		i := 0
+		for i < bound && seq[i] == ncore-1 {
+			seq[i] = 0
+			i++
+		}
+		if i == bound {
+			return nil
+		}
+		seq[i]++
+		m = newMachine()
+	}
+}
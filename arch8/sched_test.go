@@ -0,0 +1,80 @@
+package arch8
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSchedulers(t *testing.T) {
+	o := func(s Scheduler, want ...[]int) {
+		for _, w := range want {
+			got := s.Schedule(3, nil)
+			if !reflect.DeepEqual(got, w) {
+				t.Errorf("%T: got %v, want %v", s, got, w)
+			}
+		}
+	}
+
+	o(new(RoundRobin), []int{0, 1, 2}, []int{1, 2, 0}, []int{2, 0, 1})
+	o(NewWeightedScheduler([]int{2, 0}), []int{0, 2, 0})
+	o(&ScriptScheduler{Seq: []int{2, 1}}, []int{2}, []int{1}, []int{0, 1, 2})
+
+	got := NewRandomScheduler(1).Schedule(3, nil)
+	if len(got) != 3 || got[0]+got[1]+got[2] != 3 {
+		t.Errorf("random scheduler got %v", got)
+	}
+}
+
+func newCounterMachine(incr []uint32) func() *Machine {
+	return func() *Machine {
+		m := NewMachine(PageSize*64, 2)
+		m.SetCoreHalt(true)
+		for i, in := range incr {
+			m.WriteWord(InitPC+uint32(i)*4, in)
+		}
+		m.WriteWord(InitPC+uint32(len(incr))*4, HALT<<24)
+		for core := 0; core < 2; core++ {
+			m.SetReg(core, R2, 0x9000)
+			m.SetReg(core, R3, 1)
+		}
+		return m
+	}
+}
+
+func TestExplore(t *testing.T) {
+	racy := newCounterMachine([]uint32{
+		(LW << 24) | (1 << 21) | (2 << 18),       // lw r1 r2
+		(ADDI << 24) | (1 << 21) | (1 << 18) | 1, // addi r1 r1 1
+		(SW << 24) | (1 << 21) | (2 << 18),       // sw r1 r2
+	})
+
+	seen := make(map[uint32]bool)
+	err := Explore(racy, 6, 10, func(m *Machine, e *CoreExcep) error {
+		if e == nil || e.Code != ErrHalt {
+			return fmt.Errorf("got %v, want halt", e)
+		}
+		v, _ := m.ReadWord(0x9000)
+		seen[v] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !seen[1] || !seen[2] {
+		t.Fatalf("got results %v, want both 1 and 2", seen)
+	}
+
+	atomic := newCounterMachine([]uint32{
+		(2 << 18) | (3 << 15) | (1 << 21) | XADD, // xadd r1 r2 r3
+	})
+	err = Explore(atomic, 4, 10, func(m *Machine, e *CoreExcep) error {
+		if v, _ := m.ReadWord(0x9000); v != 2 {
+			return fmt.Errorf("got %d, want 2", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
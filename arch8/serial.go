@@ -36,14 +36,16 @@ const (
 	serialOutBuf = 96
 
 	serialCap = 30 // 30 bytes maximum in each pipe
+
+	serialBase = 128
+	serialSize = serialOutBuf + 32
 )
 
 // NewSerial creates a new serial controller.
-func newSerial(p *page, i intBus) *serial {
+func newSerial(p *pageOffset, i intBus) *serial {
 	ret := new(serial)
 	ret.intBus = i
-	const serialBase = 128
-	ret.p = &pageOffset{p, serialBase}
+	ret.p = p
 
 	// default interrupts
 	ret.Core = 0 // to core 0 only
@@ -170,7 +172,7 @@ func (s *serial) flush() {
 		}
 	}
 
-	if s.Output != nil {
+	if s.Output != nil && buf.Len() > 0 {
 		_, e := s.Output.Write(buf.Bytes())
 		if e != nil {
 			log.Print(e)
@@ -1,3 +1,3 @@
 package arch8
 
-var _ device = new(serial)
+var _ Device = new(serial)
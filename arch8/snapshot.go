@@ -0,0 +1,208 @@
+package arch8
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// snapshot file format
+//
+// header:  magic "e8ss", version
+// machine: npage, ncore, number of ticks run
+// cores:   regs, ring, page table root, running state and stall ticks
+//          of each core, then if in core halt mode
+// devices: serial, console, ticker, the rom if mounted, and the disk
+//          if attached; the disk image itself is not saved
+// memory:  number of pages, then page number and words of each page
+//
+// All numbers are little endian. Pages that are all zeros are skipped.
+
+var snapshotMagic = [4]byte{'e', '8', 's', 's'}
+
+// SnapshotVersion is the version of the snapshot format.
+const SnapshotVersion = 5
+
+type snapWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (w *snapWriter) write(v interface{}) {
+	if w.err != nil {
+		return
+	}
+	w.err = binary.Write(w.w, Endian, v)
+}
+
+func (w *snapWriter) writeBytes(bs []byte) {
+	w.write(uint32(len(bs)))
+	w.write(bs)
+}
+
+type snapReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *snapReader) read(v interface{}) {
+	if r.err != nil {
+		return
+	}
+	r.err = binary.Read(r.r, Endian, v)
+}
+
+// maxSnapBytes limits the length of a byte field in a snapshot.
+const maxSnapBytes = 1 << 24
+
+func (r *snapReader) readBytes() []byte {
+	var n uint32
+	r.read(&n)
+	if r.err != nil {
+		return nil
+	}
+	if n > maxSnapBytes {
+		r.err = errors.New("byte field too long")
+		return nil
+	}
+	ret := make([]byte, n)
+	r.read(ret)
+	return ret
+}
+
+type coreState struct {
+	Regs   [Nreg]uint32
+	Ring   byte
+	PTable uint32
+	State  byte
+	Stall  uint32
+}
+
+// Snapshot saves the state of the machine, including the physical
+// memory, the cores and the built-in devices. The states of the devices
+// added with AddDevice are not saved unless they are in the memory.
+// Breakpoints, watchpoints and the output writers are not saved, and
+// the restored cores start with empty tlbs.
+func (m *Machine) Snapshot(out io.Writer) error {
+	w := &snapWriter{w: bufio.NewWriter(out)}
+	w.write(snapshotMagic)
+	w.write(uint32(SnapshotVersion))
+	w.write(m.phyMem.npage)
+	w.write(uint32(len(m.cores.cores)))
+	w.write(m.ntick)
+
+	for _, c := range m.cores.cores {
+		var s coreState
+		copy(s.Regs[:], c.regs)
+		s.Ring = c.ring
+		s.PTable = c.virtMem.table()
+		s.State = c.state
+		s.Stall = c.stall
+		w.write(&s)
+	}
+	w.write(m.cores.coreHalt)
+
+	m.snapshotDevices(w)
+
+	var pns []uint32
+	for pn, p := range m.phyMem.pages {
+		if !p.isZero() {
+			pns = append(pns, pn)
+		}
+	}
+	sort.Sort(addrList(pns))
+
+	w.write(uint32(len(pns)))
+	for _, pn := range pns {
+		w.write(pn)
+		w.write(m.phyMem.pages[pn].uints)
+	}
+
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// RestoreMachine creates a machine from a snapshot saved by
+// Machine.Snapshot.
+func RestoreMachine(in io.Reader) (*Machine, error) {
+	r := &snapReader{r: bufio.NewReader(in)}
+
+	var magic [4]byte
+	var version, npage, ncore uint32
+	r.read(&magic)
+	r.read(&version)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if magic != snapshotMagic {
+		return nil, errors.New("not a machine snapshot")
+	}
+	if version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", version)
+	}
+
+	r.read(&npage)
+	r.read(&ncore)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if npage <= pageSysInfo || npage > (1<<32)/PageSize {
+		return nil, fmt.Errorf("invalid number of pages: %d", npage)
+	}
+	if ncore == 0 || ncore > 32 {
+		return nil, fmt.Errorf("invalid number of cores: %d", ncore)
+	}
+
+	m := NewMachine(npage*PageSize, int(ncore)) // wraps to 0 for 4GB
+	r.read(&m.ntick)
+	for _, c := range m.cores.cores {
+		var s coreState
+		r.read(&s)
+		copy(c.regs, s.Regs[:])
+		c.ring = s.Ring
+		c.virtMem.SetTable(s.PTable)
+		c.state = s.State
+		c.stall = s.Stall
+	}
+	r.read(&m.cores.coreHalt)
+
+	m.restoreDevices(r)
+	if r.err != nil {
+		m.Close()
+		return nil, r.err
+	}
+
+	// clear the pages created by NewMachine, but keep the pointers
+	// that are held by the devices and the cores.
+	for _, p := range m.phyMem.pages {
+		p.clear()
+	}
+
+	var n uint32
+	r.read(&n)
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		var pn uint32
+		r.read(&pn)
+		if r.err != nil {
+			break
+		}
+		p := m.phyMem.Page(pn)
+		if p == nil {
+			m.Close()
+			return nil, fmt.Errorf("page %d out of range", pn)
+		}
+		r.read(p.uints)
+		p.code = nil
+	}
+
+	if r.err != nil {
+		m.Close()
+		return nil, r.err
+	}
+	return m, nil
+}
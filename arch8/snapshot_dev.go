@@ -0,0 +1,165 @@
+package arch8
+
+// the states of the devices that are not saved in the physical memory
+
+type ioSnap struct {
+	Core   byte
+	IntIn  byte
+	IntOut byte
+}
+
+type tickerSnap struct {
+	NextTick int32
+	Interval int32
+	Noise    int32
+	Code     byte
+	Seed     int64
+	Ndraw    uint64
+}
+
+type diskSnap struct {
+	Core      byte
+	IntDone   byte
+	State     byte
+	Cmd       byte
+	CountDown int64
+}
+
+type romSnap struct {
+	Core      byte
+	IntDone   byte
+	State     byte
+	Err       byte
+	CountDown int64
+	Addr      uint32
+}
+
+func (m *Machine) snapshotDevices(w *snapWriter) {
+	w.write(&ioSnap{m.serial.Core, m.serial.IntIn, m.serial.IntOut})
+	w.write(&ioSnap{m.console.Core, m.console.IntIn, m.console.IntOut})
+
+	t := m.ticker
+	w.write(&tickerSnap{
+		NextTick: t.nextTick,
+		Interval: t.Interval,
+		Noise:    t.Noise,
+		Code:     t.Code,
+		Seed:     t.seed,
+		Ndraw:    t.src.n,
+	})
+
+	m.snapshotROM(w)
+	m.snapshotDisk(w)
+}
+
+func (m *Machine) snapshotROM(w *snapWriter) {
+	if m.rom == nil {
+		w.write(false)
+		return
+	}
+
+	r := m.rom
+	w.write(true)
+	w.writeBytes([]byte(r.root))
+	w.write(&romSnap{
+		Core:      r.Core,
+		IntDone:   r.IntDone,
+		State:     r.state,
+		Err:       r.err,
+		CountDown: int64(r.countDown),
+		Addr:      r.addr,
+	})
+	w.writeBytes(r.bs)
+}
+
+func (m *Machine) restoreDevices(r *snapReader) {
+	var s ioSnap
+	r.read(&s)
+	m.serial.Core, m.serial.IntIn, m.serial.IntOut = s.Core, s.IntIn, s.IntOut
+	r.read(&s)
+	m.console.Core, m.console.IntIn, m.console.IntOut =
+		s.Core, s.IntIn, s.IntOut
+
+	var ts tickerSnap
+	r.read(&ts)
+	t := m.ticker
+	t.nextTick = ts.NextTick
+	t.Interval = ts.Interval
+	t.Noise = ts.Noise
+	t.Code = ts.Code
+	t.setSeed(ts.Seed)
+	t.skipRand(ts.Ndraw)
+
+	m.restoreROM(r)
+	m.restoreDisk(r)
+}
+
+func (m *Machine) restoreROM(r *snapReader) {
+	var mounted bool
+	r.read(&mounted)
+	if r.err != nil || !mounted {
+		return
+	}
+
+	root := r.readBytes()
+	var rs romSnap
+	r.read(&rs)
+	bs := r.readBytes()
+	if r.err != nil {
+		return
+	}
+
+	m.MountROM(string(root))
+	rom := m.rom
+	rom.Core = rs.Core
+	rom.IntDone = rs.IntDone
+	rom.state = rs.State
+	rom.err = rs.Err
+	rom.countDown = int(rs.CountDown)
+	rom.addr = rs.Addr
+	rom.bs = bs
+}
+
+func (m *Machine) snapshotDisk(w *snapWriter) {
+	if m.disk == nil {
+		w.write(false)
+		return
+	}
+
+	d := m.disk
+	w.write(true)
+	w.writeBytes([]byte(d.path))
+	w.write(&diskSnap{
+		Core:      d.Core,
+		IntDone:   d.IntDone,
+		State:     d.state,
+		Cmd:       d.cmd,
+		CountDown: int64(d.countDown),
+	})
+}
+
+func (m *Machine) restoreDisk(r *snapReader) {
+	var attached bool
+	r.read(&attached)
+	if r.err != nil || !attached {
+		return
+	}
+
+	path := r.readBytes()
+	var ds diskSnap
+	r.read(&ds)
+	if r.err != nil {
+		return
+	}
+
+	if err := m.AttachDisk(string(path)); err != nil {
+		r.err = err
+		return
+	}
+	d := m.disk
+	d.Core = ds.Core
+	d.IntDone = ds.IntDone
+	d.state = ds.State
+	d.cmd = ds.Cmd
+	d.countDown = int(ds.CountDown)
+}
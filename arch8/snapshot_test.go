@@ -0,0 +1,55 @@
+package arch8
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	m := NewMachine(PageSize*64, 2)
+	m.WriteWord(InitPC, (ADDI<<24)|(1<<21)|(1<<18)|1) // addi r1 r1 1
+	m.WriteWord(InitPC+4, (SW<<24)|(1<<21)|(2<<18))   // sw r1 r2
+	m.WriteWord(InitPC+8, (J<<30)|0x3ffffffd)         // j -3
+	m.SetReg(0, R2, 0x9000)
+	m.SetReg(1, R2, 0x9004)
+	m.RandSeed(7)
+	m.ticker.Interval = 10
+
+	snapshot := func(m *Machine) []byte {
+		buf := new(bytes.Buffer)
+		if err := m.Snapshot(buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	run := func(m *Machine, n int) {
+		if _, e := m.Run(n); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	run(m, 100)
+	saved := snapshot(m)
+	run(m, 1000)
+
+	m2, err := RestoreMachine(bytes.NewReader(saved))
+	if err != nil {
+		t.Fatal(err)
+	}
+	run(m2, 1000)
+
+	if m2.ntick != m.ntick {
+		t.Fatalf("ntick got %d, want %d", m2.ntick, m.ntick)
+	}
+	if m2.Reg(1, R1) != m.Reg(1, R1) {
+		t.Fatalf("r1 got %d, want %d", m2.Reg(1, R1), m.Reg(1, R1))
+	}
+	if !bytes.Equal(snapshot(m), snapshot(m2)) {
+		t.Fatal("restored machine diverged")
+	}
+
+	saved[0] = 'x'
+	if _, err := RestoreMachine(bytes.NewReader(saved)); err == nil {
+		t.Fatal("bad magic accepted")
+	}
+}
@@ -5,11 +5,27 @@ import (
 	"time"
 )
 
+// countedSource is a random source that counts the number of values
+// generated, so that its state can be saved and replayed.
+type countedSource struct {
+	rand.Source
+	n uint64
+}
+
+func (s *countedSource) Int63() int64 {
+	s.n++
+	return s.Source.Int63()
+}
+
 // Ticker is a device that generates time interrupts.
 type ticker struct {
 	intBus   intBus
 	nextTick int32
 
+	seed  int64
+	src   *countedSource
+	input *inputLog
+
 	Interval int32
 	Noise    int32
 	Rand     *rand.Rand
@@ -23,12 +39,26 @@ func newTicker(bus intBus) *ticker {
 
 	ret.Interval = 2000
 	ret.Noise = 10
-	ret.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	ret.setSeed(time.Now().UnixNano())
 	ret.Code = ErrTimer // time interrupt code
 
 	return ret
 }
 
+// setSeed resets the random number generator with a seed.
+func (t *ticker) setSeed(s int64) {
+	t.seed = s
+	t.src = &countedSource{Source: rand.NewSource(s)}
+	t.Rand = rand.New(t.src)
+}
+
+// skipRand skips n values in the random source.
+func (t *ticker) skipRand(n uint64) {
+	for i := uint64(0); i < n; i++ {
+		t.src.Int63()
+	}
+}
+
 func (t *ticker) reset() {
 	if t.Noise < 0 {
 		panic("negative ticker noise")
@@ -37,6 +67,13 @@ func (t *ticker) reset() {
 		panic("negative ticker interval")
 	}
 
+	if t.input.replaying() {
+		if ev, ok := t.input.expect(inputTicker); ok {
+			t.nextTick = int32(ev.arg)
+		}
+		return
+	}
+
 	noise := int32(0)
 	if t.Noise > 0 {
 		noise = t.Rand.Int31n(t.Noise) - t.Noise/2
@@ -48,6 +85,9 @@ func (t *ticker) reset() {
 	}
 
 	t.nextTick = next
+	if t.input.recording() {
+		t.input.record(inputTicker, uint32(next), nil)
+	}
 }
 
 // Tick decreases the ticking counter. If the counter reaches 0,
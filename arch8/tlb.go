@@ -0,0 +1,116 @@
+package arch8
+
+// tlbSize is the number of entries in a tlb.
+const tlbSize = 64
+
+// tlbEntry caches the translation of a virtual page.
+type tlbEntry struct {
+	vpn   uint32
+	ppn   uint32
+	valid bool
+	user  bool // accessible in user mode
+	exec  bool // executable
+	dirty bool // translated for writing, so also writable
+}
+
+// TLBStats is the statistics of a tlb.
+type TLBStats struct {
+	Hits    uint64
+	Misses  uint64
+	Flushes uint64
+}
+
+// tlb is a direct mapped translation lookaside buffer of a core. Like a
+// real one, it is not updated when the page table changes, so the kernel
+// needs to flush it.
+type tlb struct {
+	entries [tlbSize]tlbEntry
+	stats   TLBStats
+}
+
+// lookup returns the entry of the virtual page, or nil if it misses.
+// When write is true, an entry that is not translated for writing is
+// also a miss, so that the dirty bits in the page table are set.
+func (t *tlb) lookup(vpn uint32, write bool) *tlbEntry {
+	e := &t.entries[vpn%tlbSize]
+	if !e.valid || e.vpn != vpn || (write && !e.dirty) {
+		t.stats.Misses++
+		return nil
+	}
+	t.stats.Hits++
+	return e
+}
+
+// fill saves the last translation of the page table.
+func (t *tlb) fill(vpn uint32, pt *pageTable, write bool) {
+	t.entries[vpn%tlbSize] = tlbEntry{
+		vpn:   vpn,
+		ppn:   pt.pte2.pn(),
+		valid: true,
+		user:  pt.pte1.testBit(pteUser) && pt.pte2.testBit(pteUser),
+		exec:  pt.exec(),
+		dirty: write,
+	}
+}
+
+// flush invalidates all the entries.
+func (t *tlb) flush() {
+	for i := range t.entries {
+		t.entries[i].valid = false
+	}
+	t.stats.Flushes++
+}
+
+// flushPage invalidates the entry of a virtual page.
+func (t *tlb) flushPage(vpn uint32) {
+	e := &t.entries[vpn%tlbSize]
+	if e.vpn == vpn {
+		e.valid = false
+	}
+}
+
+// memory access types
+const (
+	accRead = iota
+	accWrite
+	accExec // instruction fetch
+)
+
+// translate translates with the tlb, and walks the page table when it
+// misses.
+func (vm *virtMemory) translate(addr uint32, ring byte, acc int) (
+	uint32, *Excep,
+) {
+	vpn := addr / PageSize
+	write := acc == accWrite
+	if e := vm.tlb.lookup(vpn, write); e != nil {
+		if ring > 0 && !e.user {
+			return 0, newPageFault(addr)
+		}
+		if acc == accExec && !e.exec {
+			return 0, newPageNoExec(addr)
+		}
+		return e.ppn*PageSize + addr%PageSize, nil
+	}
+
+	var pa uint32
+	var e *Excep
+	if write {
+		pa, e = vm.ptable.TranslateWrite(addr, ring)
+	} else {
+		pa, e = vm.ptable.TranslateRead(addr, ring)
+	}
+	if e != nil {
+		return 0, e
+	}
+	vm.tlb.fill(vpn, vm.ptable, write)
+	if acc == accExec && !vm.ptable.exec() {
+		return 0, newPageNoExec(addr)
+	}
+	return pa, nil
+}
+
+// TLBStats returns the tlb statistics of a core.
+func (m *Machine) TLBStats(core int) TLBStats {
+	return m.cores.cores[core].virtMem.tlb.stats
+}
@@ -0,0 +1,59 @@
+package arch8
+
+import (
+	"testing"
+)
+
+func TestTLB(t *testing.T) {
+	m := NewMachine(PageSize*64, 1)
+	pte := func(ppn uint32) uint32 {
+		e := ptEntry(ppn * PageSize)
+		e.setBit(pteValid)
+		return uint32(e)
+	}
+	m.WriteWord(0x10000, pte(0x11))
+	m.WriteWord(0x11000+0x20*4, pte(0x30))
+
+	vm := m.cores.cores[0].virtMem
+	vm.SetTable(0x10000)
+
+	const va = 0x20004
+	m.WriteWord(0x30004, 7)
+	for i := 0; i < 3; i++ {
+		if v, e := vm.ReadWord(va, 0); e != nil || v != 7 {
+			t.Fatalf("got %d, %v", v, e)
+		}
+	}
+	stats := m.TLBStats(0)
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Fatalf("got stats %+v", stats)
+	}
+
+	// write through an entry filled for reading walks again
+	if e := vm.WriteWord(va, 0, 8); e != nil {
+		t.Fatal(e)
+	}
+	w, _ := m.ReadWord(0x11000 + 0x20*4)
+	if !ptEntry(w).testBit(pteDirty) {
+		t.Fatal("dirty bit not set")
+	}
+	if m.TLBStats(0).Misses != 2 {
+		t.Fatal("write on clean entry not counted as a miss")
+	}
+
+	// remap the page, the tlb stays stale until flushed
+	m.WriteWord(0x11000+0x20*4, pte(0x31))
+	m.WriteWord(0x31004, 9)
+	if v, _ := vm.ReadWord(va, 0); v != 8 {
+		t.Fatalf("got %d, want the stale 8", v)
+	}
+	vm.tlb.flushPage(va / PageSize)
+	if v, _ := vm.ReadWord(va, 0); v != 9 {
+		t.Fatalf("got %d, want 9 after flushing", v)
+	}
+
+	// user mode is checked on hits
+	if _, e := vm.ReadWord(va, 1); e == nil || e.Code != ErrPageFault {
+		t.Fatalf("got %v, want page fault", e)
+	}
+}
@@ -0,0 +1,83 @@
+package arch8
+
+// MemAccess is a memory access performed by an instruction.
+type MemAccess struct {
+	VirtAddr uint32
+	PhyAddr  uint32
+	Size     uint8 // 1 or 4
+	Write    bool
+}
+
+// RegWrite is a register changed by an instruction.
+type RegWrite struct {
+	Reg   int
+	Value uint32
+}
+
+// TraceEntry is the trace of one executed instruction. The instruction
+// fetch is not counted as a memory access. The PC is listed as a
+// written register only when it is not simply increased by 4.
+type TraceEntry struct {
+	Core  int
+	PC    uint32
+	Inst  uint32
+	Regs  []RegWrite
+	Mem   []MemAccess
+	Excep *Excep // the exception that the instruction met, if any
+}
+
+// Tracer receives a trace entry after each instruction is executed.
+// The entry is reused after Trace returns.
+type Tracer interface {
+	Trace(e *TraceEntry)
+}
+
+// coreTrace builds the trace entry of a core.
+type coreTrace struct {
+	tracer Tracer
+	entry  TraceEntry
+	regs   [Nreg]uint32
+}
+
+func (t *coreTrace) access(va, pa uint32, size uint8, write bool) {
+	t.entry.Mem = append(t.entry.Mem, MemAccess{va, pa, size, write})
+}
+
+// begin starts tracing an instruction, after it has been fetched.
+func (t *coreTrace) begin(c *cpu, pc, in uint32) {
+	t.entry.Core = int(c.index)
+	t.entry.PC = pc
+	t.entry.Inst = in
+	t.entry.Regs = t.entry.Regs[:0]
+	t.entry.Mem = t.entry.Mem[:0]
+	t.entry.Excep = nil
+	copy(t.regs[:], c.regs)
+	t.regs[PC] = pc + 4
+	c.virtMem.trace = t
+}
+
+// end finishes tracing the instruction and sends the entry to the tracer.
+func (t *coreTrace) end(c *cpu, e *Excep) {
+	c.virtMem.trace = nil
+	if e == nil {
+		for i, v := range c.regs {
+			if v != t.regs[i] {
+				t.entry.Regs = append(t.entry.Regs, RegWrite{i, v})
+			}
+		}
+	}
+	t.entry.Excep = e
+	t.tracer.Trace(&t.entry)
+}
+
+// SetTracer sets the tracer that receives the trace of every instruction
+// executed on all the cores. A nil tracer stops the tracing.
+func (m *Machine) SetTracer(t Tracer) {
+	for _, c := range m.cores.cores {
+		if t == nil {
+			c.trace = nil
+		} else {
+			c.trace = &coreTrace{tracer: t}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package arch8
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// binary trace file format
+//
+// header: magic "e8tr", version
+// entries:
+//   core, flags (bit 0: has exception), pc, inst,
+//   number of registers, then register and value of each,
+//   number of memory accesses, then virtual address, physical address
+//   and size (bit 7 set for writes) of each,
+//   exception code and arg if there is one.
+//
+// All numbers are little endian.
+
+var traceMagic = [4]byte{'e', '8', 't', 'r'}
+
+// TraceVersion is the version of the binary trace format.
+const TraceVersion = 1
+
+const (
+	traceHasExcep = 0x1
+	traceWrite    = 0x80
+)
+
+// TraceWriter is a tracer that writes the trace entries in the compact
+// binary format. Call Flush after tracing to check for errors.
+type TraceWriter struct {
+	w   *bufio.Writer
+	err error
+	buf []byte
+}
+
+// NewTraceWriter creates a binary trace writer.
+func NewTraceWriter(w io.Writer) *TraceWriter {
+	ret := &TraceWriter{w: bufio.NewWriter(w)}
+	ret.buf = append(ret.buf, traceMagic[:]...)
+	ret.buf = appendWord(ret.buf, TraceVersion)
+	_, ret.err = ret.w.Write(ret.buf)
+	return ret
+}
+
+func appendWord(buf []byte, w uint32) []byte {
+	var bs [4]byte
+	Endian.PutUint32(bs[:], w)
+	return append(buf, bs[:]...)
+}
+
+// Trace writes an entry.
+func (w *TraceWriter) Trace(e *TraceEntry) {
+	if w.err != nil {
+		return
+	}
+
+	flags := byte(0)
+	if e.Excep != nil {
+		flags |= traceHasExcep
+	}
+
+	buf := append(w.buf[:0], byte(e.Core), flags)
+	buf = appendWord(buf, e.PC)
+	buf = appendWord(buf, e.Inst)
+	buf = append(buf, byte(len(e.Regs)))
+	for _, r := range e.Regs {
+		buf = append(buf, byte(r.Reg))
+		buf = appendWord(buf, r.Value)
+	}
+	buf = append(buf, byte(len(e.Mem)))
+	for _, m := range e.Mem {
+		buf = appendWord(buf, m.VirtAddr)
+		buf = appendWord(buf, m.PhyAddr)
+		size := m.Size
+		if m.Write {
+			size |= traceWrite
+		}
+		buf = append(buf, size)
+	}
+	if e.Excep != nil {
+		buf = append(buf, e.Excep.Code)
+		buf = appendWord(buf, e.Excep.Arg)
+	}
+
+	w.buf = buf
+	_, w.err = w.w.Write(buf)
+}
+
+// Flush flushes the buffered entries, and returns the first error met.
+func (w *TraceWriter) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// TraceReader reads the trace entries written by a TraceWriter.
+type TraceReader struct {
+	r *bufio.Reader
+}
+
+// NewTraceReader creates a binary trace reader.
+func NewTraceReader(r io.Reader) (*TraceReader, error) {
+	br := bufio.NewReader(r)
+	var magic [4]byte
+	var version uint32
+	if err := binary.Read(br, Endian, &magic); err != nil {
+		return nil, err
+	}
+	if magic != traceMagic {
+		return nil, errors.New("not a trace file")
+	}
+	if err := binary.Read(br, Endian, &version); err != nil {
+		return nil, err
+	}
+	if version != TraceVersion {
+		return nil, fmt.Errorf("unsupported trace version %d", version)
+	}
+	return &TraceReader{r: br}, nil
+}
+
+// Next reads the next trace entry. It returns io.EOF at the end of the
+// trace.
+func (r *TraceReader) Next() (*TraceEntry, error) {
+	var h struct {
+		Core  byte
+		Flags byte
+		PC    uint32
+		Inst  uint32
+		Nreg  byte
+	}
+	if err := binary.Read(r.r, Endian, &h); err != nil {
+		return nil, err
+	}
+
+	ret := &TraceEntry{Core: int(h.Core), PC: h.PC, Inst: h.Inst}
+	for i := 0; i < int(h.Nreg); i++ {
+		var reg struct {
+			Reg   byte
+			Value uint32
+		}
+		if err := binary.Read(r.r, Endian, &reg); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		ret.Regs = append(ret.Regs, RegWrite{int(reg.Reg), reg.Value})
+	}
+
+	var nmem byte
+	if err := binary.Read(r.r, Endian, &nmem); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	for i := 0; i < int(nmem); i++ {
+		var m struct {
+			VirtAddr uint32
+			PhyAddr  uint32
+			Size     byte
+		}
+		if err := binary.Read(r.r, Endian, &m); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		ret.Mem = append(ret.Mem, MemAccess{
+			VirtAddr: m.VirtAddr,
+			PhyAddr:  m.PhyAddr,
+			Size:     m.Size &^ traceWrite,
+			Write:    m.Size&traceWrite != 0,
+		})
+	}
+
+	if h.Flags&traceHasExcep != 0 {
+		var e struct {
+			Code byte
+			Arg  uint32
+		}
+		if err := binary.Read(r.r, Endian, &e); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		ret.Excep = newExcep(e.Code, fmt.Sprintf("exception %d", e.Code))
+		ret.Excep.Arg = e.Arg
+	}
+
+	return ret, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
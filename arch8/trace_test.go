@@ -0,0 +1,80 @@
+package arch8
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type traceList []*TraceEntry
+
+func (l *traceList) Trace(e *TraceEntry) {
+	cp := *e
+	cp.Regs = append([]RegWrite(nil), e.Regs...)
+	cp.Mem = append([]MemAccess(nil), e.Mem...)
+	*l = append(*l, &cp)
+}
+
+func TestTrace(t *testing.T) {
+	m := NewMachine(PageSize*64, 1)
+	m.WriteWord(InitPC, (ADDI<<24)|(1<<21)|(1<<18)|1) // addi r1 r1 1
+	m.WriteWord(InitPC+4, (SW<<24)|(1<<21)|(2<<18))   // sw r1 r2
+	m.WriteWord(InitPC+8, (LB<<24)|(3<<21)|(2<<18))   // lb r3 r2
+	m.WriteWord(InitPC+12, HALT<<24)
+	m.SetReg(0, R2, 0x9000)
+
+	var l traceList
+	buf := new(bytes.Buffer)
+	w := NewTraceWriter(buf)
+	m.SetTracer(&l)
+	m.Run(2)
+	m.SetTracer(w)
+	m.Run(0)
+
+	for _, e := range l {
+		w.Trace(e)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*TraceEntry{
+		{PC: InitPC, Inst: (ADDI << 24) | (1 << 21) | (1 << 18) | 1,
+			Regs: []RegWrite{{R1, 1}},
+		},
+		{PC: InitPC + 4, Inst: (SW << 24) | (1 << 21) | (2 << 18),
+			Mem: []MemAccess{{0x9000, 0x9000, 4, true}},
+		},
+	}
+	if !reflect.DeepEqual([]*TraceEntry(l), want) {
+		t.Fatalf("got trace %v", l)
+	}
+
+	r, err := NewTraceReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*TraceEntry
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d entries, want 4", len(got))
+	}
+	if got[0].Mem[0].Size != 1 || got[0].Regs[0].Reg != R3 {
+		t.Fatalf("wrong lb trace: %v", got[0])
+	}
+	if got[1].Excep == nil || got[1].Excep.Code != ErrHalt {
+		t.Fatalf("halt not traced: %v", got[1])
+	}
+	if !reflect.DeepEqual(got[2:], want) {
+		t.Fatalf("trace changed after writing and reading")
+	}
+}
@@ -5,6 +5,30 @@ package arch8
 type virtMemory struct {
 	phyMem *phyMemory
 	ptable *pageTable
+	tlb    tlb
+
+	debug *debugger  // watches the writes when not nil
+	trace *coreTrace // records the accesses when not nil
+
+	resv *reservations // shared by all the cores
+
+	fault memFault // the last access that failed
+}
+
+// memFault saves the addresses of a memory access that failed.
+type memFault struct {
+	va, pa uint32
+	hasPA  bool // translated, but failed on the physical address
+}
+
+func (vm *virtMemory) failVirt(va uint32, e *Excep) *Excep {
+	vm.fault = memFault{va: va}
+	return e
+}
+
+func (vm *virtMemory) failPhy(va, pa uint32, e *Excep) *Excep {
+	vm.fault = memFault{va: va, pa: pa, hasPA: true}
+	return e
 }
 
 // NewVirtMemory creates a new virtual address space with no page table.
@@ -17,7 +41,9 @@ func newVirtMemory(phy *phyMemory) *virtMemory {
 // SetTable applies a particular pagetable at a physical memory position.
 // If the address is not page size aligned, it will be aligned down.
 // If the address is 0, it will use direct mapping.
+// It also flushes the tlb.
 func (vm *virtMemory) SetTable(root uint32) {
+	vm.tlb.flush()
 	if root == 0 {
 		vm.ptable = nil
 	} else {
@@ -25,53 +51,145 @@ func (vm *virtMemory) SetTable(root uint32) {
 	}
 }
 
+// table returns the physical address of the page table,
+// or 0 when direct mapping is used.
+func (vm *virtMemory) table() uint32 {
+	if vm.ptable == nil {
+		return 0
+	}
+	return vm.ptable.root
+}
+
 func (vm *virtMemory) transRead(addr uint32, ring byte) (uint32, *Excep) {
 	if vm.ptable == nil {
 		return addr, nil
 	}
-	return vm.ptable.TranslateRead(addr, ring)
+	pa, e := vm.translate(addr, ring, accRead)
+	if e != nil {
+		return 0, vm.failVirt(addr, e)
+	}
+	return pa, nil
+}
+
+func (vm *virtMemory) transExec(addr uint32, ring byte) (uint32, *Excep) {
+	if vm.ptable == nil {
+		return addr, nil
+	}
+	pa, e := vm.translate(addr, ring, accExec)
+	if e != nil {
+		return 0, vm.failVirt(addr, e)
+	}
+	return pa, nil
 }
 
 func (vm *virtMemory) transWrite(addr uint32, ring byte) (uint32, *Excep) {
 	if vm.ptable == nil {
 		return addr, nil
 	}
-	return vm.ptable.TranslateWrite(addr, ring)
+	pa, e := vm.translate(addr, ring, accWrite)
+	if e != nil {
+		return 0, vm.failVirt(addr, e)
+	}
+	return pa, nil
 }
 
 // ReadWord reads the byte at the given virtual address.
 func (vm *virtMemory) ReadWord(addr uint32, ring byte) (uint32, *Excep) {
-	addr, e := vm.transRead(addr, ring)
+	pa, e := vm.transRead(addr, ring)
 	if e != nil {
 		return 0, e
 	}
-	return vm.phyMem.ReadWord(addr)
+	if vm.trace != nil {
+		vm.trace.access(addr, pa, 4, false)
+	}
+	v, e := vm.phyMem.ReadWord(pa)
+	if e != nil {
+		return 0, vm.failPhy(addr, pa, e)
+	}
+	return v, nil
+}
+
+// FetchWord reads the instruction at the given virtual address, which
+// must be in an executable page.
+func (vm *virtMemory) FetchWord(addr uint32, ring byte) (uint32, *Excep) {
+	pa, e := vm.transExec(addr, ring)
+	if e != nil {
+		return 0, e
+	}
+	if vm.trace != nil {
+		vm.trace.access(addr, pa, 4, false)
+	}
+	v, e := vm.phyMem.ReadWord(pa)
+	if e != nil {
+		return 0, vm.failPhy(addr, pa, e)
+	}
+	return v, nil
 }
 
 // WriteWord writes the byte at the given virtual address.
 func (vm *virtMemory) WriteWord(addr uint32, ring byte, v uint32) *Excep {
-	addr, e := vm.transWrite(addr, ring)
+	pa, e := vm.transWrite(addr, ring)
 	if e != nil {
 		return e
 	}
-	return vm.phyMem.WriteWord(addr, v)
+	if vm.trace != nil {
+		vm.trace.access(addr, pa, 4, true)
+	}
+	if e := vm.phyMem.WriteWord(pa, v); e != nil {
+		return vm.failPhy(addr, pa, e)
+	}
+	if vm.resv != nil {
+		vm.resv.written(pa)
+	}
+	if vm.debug != nil {
+		vm.debug.memWrite(addr, pa, 4)
+	}
+	return nil
 }
 
 // ReadByte reads the byte at the given virtual address.
 func (vm *virtMemory) ReadByte(addr uint32, ring byte) (byte, *Excep) {
-	addr, e := vm.transRead(addr, ring)
+	pa, e := vm.transRead(addr, ring)
 	if e != nil {
 		return 0, e
 	}
-	return vm.phyMem.ReadByte(addr)
+	if vm.trace != nil {
+		vm.trace.access(addr, pa, 1, false)
+	}
+	b, e := vm.phyMem.ReadByte(pa)
+	if e != nil {
+		return 0, vm.failPhy(addr, pa, e)
+	}
+	return b, nil
 }
 
 // WriteByte writes a byte at the given virtual address under
 // a certain ring.
 func (vm *virtMemory) WriteByte(addr uint32, ring byte, v byte) *Excep {
-	addr, e := vm.transWrite(addr, ring)
+	pa, e := vm.transWrite(addr, ring)
 	if e != nil {
 		return e
 	}
-	return vm.phyMem.WriteByte(addr, v)
+	if vm.trace != nil {
+		vm.trace.access(addr, pa, 1, true)
+	}
+	if e := vm.phyMem.WriteByte(pa, v); e != nil {
+		return vm.failPhy(addr, pa, e)
+	}
+	if vm.resv != nil {
+		vm.resv.written(pa)
+	}
+	if vm.debug != nil {
+		vm.debug.memWrite(addr, pa, 1)
+	}
+	return nil
+}
+
+// Translate translates a virtual address into a physical address without
+// touching the use and dirty bits in the page table.
+func (vm *virtMemory) Translate(addr uint32, ring byte) (uint32, *Excep) {
+	if vm.ptable == nil {
+		return addr, nil
+	}
+	return vm.ptable.Translate(addr, ring)
 }
@@ -0,0 +1,12 @@
+package ast
+
+import (
+	"e8vm.io/e8vm/lex8"
+)
+
+// Const is a named constant declaration.
+type Const struct {
+	Kw, Name, Assign *lex8.Token
+	Value            *lex8.Token
+	Semi             *lex8.Token
+}
@@ -18,4 +18,6 @@ type File struct {
 var decls = []interface{}{
 	new(Func),
 	new(Var),
+	new(Const),
+	new(Macro),
 }
@@ -0,0 +1,16 @@
+package ast
+
+import (
+	"e8vm.io/e8vm/lex8"
+)
+
+// Macro is a parameterized sequence of instructions. A statement in a
+// function that starts with the macro name expands into the statements
+// of the macro, with the parameters replaced by the arguments.
+type Macro struct {
+	Params []*lex8.Token
+	Stmts  []*FuncStmt
+
+	Kw, Name             *lex8.Token
+	Lbrace, Rbrace, Semi *lex8.Token
+}
@@ -17,7 +17,7 @@ func BuildBareFunc(f string, rc io.ReadCloser) ([]byte, []*lex8.Error) {
 
 	// resolving pass
 	log := lex8.NewErrorList()
-	rfunc := resolveFunc(log, fn)
+	rfunc := resolveFunc(log, fn, nil)
 	if es := log.Errs(); es != nil {
 		return nil, es
 	}
@@ -0,0 +1,84 @@
+package asm8
+
+import (
+	"e8vm.io/e8vm/sym8"
+)
+
+// importedConst returns the symbol of a constant of an imported package,
+// or nil if the package is not an assembly package or has no constant of
+// the name.
+func importedConst(p *importStmt, name string) *sym8.Symbol {
+	l, ok := p.linkable.(*lib)
+	if !ok {
+		return nil
+	}
+	sym := l.query(name)
+	if sym == nil || sym.Type != SymConst {
+		return nil
+	}
+	return sym
+}
+
+// queryConst returns the constant that the symbol in the statement refers
+// to, or nil if the symbol is not a constant. A constant of an imported
+// package must be public, and the package must be an assembly package.
+func queryConst(b *builder, s *funcStmt) *constDecl {
+	if s.pkg == "" {
+		sym := b.scope.Query(s.sym)
+		if sym == nil || sym.Type != SymConst {
+			return nil
+		}
+		return sym.Item.(*constDecl)
+	}
+
+	sym := b.scope.Query(s.pkg)
+	if sym == nil || sym.Type != SymImport {
+		return nil
+	}
+	p := sym.Item.(*importStmt)
+	sym = importedConst(p, s.sym)
+	if sym == nil {
+		return nil
+	}
+
+	b.pkgUsed[p.as] = struct{}{}
+	if !sym8.IsPublic(s.sym) {
+		t := s.symTok
+		b.Errorf(t.Pos, "%q is not public", t.Lit)
+	}
+	return sym.Item.(*constDecl)
+}
+
+// fitsImm checks if a value fits in a 16-bit immediate, signed or
+// unsigned.
+func fitsImm(v uint32) bool {
+	return v <= 0xffff || v >= 0xffff8000
+}
+
+// pairedLui checks if the previous instruction is a lui that loads the
+// high 16 bits of the same symbol.
+func pairedLui(s, prev *funcStmt) bool {
+	return prev != nil && prev.fill == fillHigh &&
+		prev.pkg == s.pkg && prev.sym == s.sym
+}
+
+// fillConst fills the immediate of an instruction with a constant. Like
+// the address of a symbol, lui takes the high 16 bits of the value, and
+// other instructions take the low 16 bits. The low 16 bits are only
+// taken when the value fits in an immediate, or when prev is a lui that
+// takes the high 16 bits.
+func fillConst(b *builder, s, prev *funcStmt, c *constDecl) {
+	switch s.fill {
+	case fillHigh:
+		s.inst.inst |= c.value >> 16
+	case fillLow:
+		if !fitsImm(c.value) && !pairedLui(s, prev) {
+			t := s.symTok
+			b.Errorf(t.Pos, "const %q out of 16-bit range", t.Lit)
+			return
+		}
+		s.inst.inst |= c.value & 0xffff
+	default:
+		panic("invalid const filling")
+	}
+}
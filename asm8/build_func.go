@@ -131,7 +131,8 @@ func init() {
 // in the current package context.
 //
 // this function only resolves symbol that requires linking
-// which are variables and functions
+// which are variables and functions; constants are filled
+// before linking, so they are only resolved for reporting errors
 func resolveSymbol(b *builder, s *funcStmt) (typ int, pkg, name string) {
 	t := s.symTok
 
@@ -152,9 +153,10 @@ func resolveSymbol(b *builder, s *funcStmt) (typ int, pkg, name string) {
 			pkg = b.pkgPath(p.as)        // package index, based on alias
 			b.pkgUsed[p.as] = struct{}{} // mark pkg used
 
-			// TODO: support consts in assembly
 			sym = p.lib.SymbolByName(s.sym) // find the symbol
-			if sym != nil {
+			if sym == nil && importedConst(p, s.sym) != nil {
+				typ = SymConst
+			} else if sym != nil {
 				if sym.Type == link8.SymFunc {
 					typ = SymFunc
 				} else if sym.Type == link8.SymVar {
@@ -171,9 +173,6 @@ func resolveSymbol(b *builder, s *funcStmt) (typ int, pkg, name string) {
 	switch typ {
 	case SymNone:
 		b.Errorf(t.Pos, "%q not found", t.Lit)
-	case SymConst:
-		b.Errorf(t.Pos, "const symbol filling not implemented yet")
-		typ = SymNone // report as error
 	case SymImport, SymLabel:
 		b.Errorf(t.Pos, "cannot link %s %q", symStr(typ), t.Lit)
 		typ = SymNone // report as error
@@ -213,13 +212,22 @@ func linkSymbol(b *builder, s *funcStmt, f *link8.Func) {
 // Builder b.
 func makeFuncObj(b *builder, f *funcDecl) *link8.Func {
 	ret := link8.NewFunc()
+	var prev *funcStmt // the previous instruction
 	for _, s := range f.stmts {
 		if s.isLabel() {
 			continue // skip labels
 		}
+		isConst := false
+		if s.fill == fillHigh || s.fill == fillLow {
+			if c := queryConst(b, s); c != nil {
+				fillConst(b, s, prev, c)
+				isConst = true
+			}
+		}
 		ret.AddInst(s.inst.inst)
+		prev = s
 
-		if !(s.fill > fillNone && s.fill < fillLabel) {
+		if isConst || !(s.fill > fillNone && s.fill < fillLabel) {
 			continue // only care about fillHigh, fillLow and fillLink
 		}
 
@@ -227,7 +235,7 @@ func makeFuncObj(b *builder, f *funcDecl) *link8.Func {
 	}
 
 	if ret.TooLarge() {
-		b.Errorf(f.Name.Pos, "too many instructions in func", f.Name.Lit)
+		b.Errorf(f.Name.Pos, "too many instructions in func %q", f.Name.Lit)
 	}
 
 	return ret
@@ -39,6 +39,17 @@ func declareFile(b *builder, pkg *pkg, file *file) {
 		b.curPkg.Declare(sym)
 		// b.index(t.Lit, b.curPkg.Declare(sym))
 	}
+
+	// declare constants
+	for _, c := range file.consts {
+		t := c.Name
+		sym := sym8.Make(b.symPkg, t.Lit, SymConst, c, t.Pos)
+		if !declareSymbol(b, sym) {
+			continue
+		}
+
+		b.curPkg.Declare(sym)
+	}
 }
 
 func buildPkgScope(b *builder, pkg *pkg) {
@@ -0,0 +1,131 @@
+package asm8
+
+import (
+	"strings"
+	"testing"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/build8"
+	"e8vm.io/e8vm/lex8"
+)
+
+const serialSrc = `
+const Addr = 0x2000
+const Send = 0x100
+const private = 3
+`
+
+func buildMain(src string) ([]byte, []*lex8.Error) {
+	home := build8.NewMemHome(Lang())
+	home.NewPkg("serial").AddFile("", "serial.s", serialSrc)
+	home.NewPkg("main").AddFile("", "main.s", src)
+
+	b := build8.NewBuilder(home)
+	if es := b.BuildAll(false); es != nil {
+		return nil, es
+	}
+	return home.Bin("main"), nil
+}
+
+func TestConstAndMacro(t *testing.T) {
+	const src = `
+	import {
+		"serial"
+	}
+
+	const Char = 0x41 // 'A'
+	const Big = 0x12345678
+
+	macro putc c {
+		ori r2 r0 serial.Addr
+		addi r3 r0 c
+		ori r3 r3 serial.Send
+		sw r3 r2
+	}
+
+	func main {
+		lui r1 Big
+		ori r1 r1 Big
+		lui r4 0x1234
+		ori r4 r4 0x5678
+		bne r1 r4 .fail
+		putc Char
+		putc 0x42
+		halt
+	.fail
+		putc 0x58
+		halt
+	}`
+
+	bs, es := buildMain(src)
+	if es != nil {
+		for _, e := range es {
+			t.Log(e)
+		}
+		t.Fatal("build failed")
+	}
+
+	_, out, e := arch8.RunImageOutput(bs, 1000)
+	if !arch8.IsHalt(e) {
+		t.Fatalf("did not halt gracefully: %v", e)
+	}
+	if out != "AB" {
+		t.Errorf("got output %q, want %q", out, "AB")
+	}
+}
+
+func TestConstAndMacro_bad(t *testing.T) {
+	o := func(src, err string) {
+		_, es := buildMain(src)
+		if es == nil {
+			t.Errorf("%q: should fail", src)
+			return
+		}
+		for _, e := range es {
+			if strings.Contains(e.Error(), err) {
+				return
+			}
+		}
+		t.Errorf("%q: got %v, want error %q", src, es, err)
+	}
+
+	o("const X = 0x100000000\nfunc main { halt }", "out of 32-bit range")
+	o("const X = y\nfunc main { halt }", "invalid const value")
+	o("const X = 1\nconst X = 2\nfunc main { halt }", "already declared")
+	o("const X = 1\nfunc main { j X }", "is not a function")
+	o("const X = 0x12345\nfunc main { addi r1 r0 X }", "16-bit range")
+	o("const X = 0x12345\nconst Y = 0x12345\n"+
+		"func main {\nlui r1 Y\nori r1 r1 X\n}", "16-bit range",
+	)
+	o(`import { "serial" }
+	func main { addi r1 r0 serial.private }`, "is not public")
+	o("macro m a a { halt }\nfunc main { halt }", "duplicate macro")
+	o("macro m { halt }\nmacro m { halt }\nfunc main { halt }",
+		"already declared",
+	)
+	o("macro m a { halt }\nfunc main { m }", "needs 1 arguments")
+	o("macro add { halt }\nfunc main { halt }", "is an instruction")
+	o("macro main { halt }\nfunc main { halt }", "already declared")
+	o("const m = 1\nmacro m { halt }\nfunc main { halt }",
+		"already declared",
+	)
+	o("macro m {\n.l\n}\nfunc main { halt }", "label not allowed")
+}
+
+func TestMacroConflictOrder(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		home := build8.NewMemHome(Lang())
+		pkg := home.NewPkg("main")
+		pkg.AddFile("", "a.s", "macro m { halt }\nfunc main { halt }")
+		pkg.AddFile("", "b.s", "macro m { halt }")
+		pkg.AddFile("", "c.s", "macro m { halt }")
+
+		es := build8.NewBuilder(home).BuildAll(false)
+		if len(es) != 4 {
+			t.Fatalf("got %d errors, want 4", len(es))
+		}
+		if f := es[0].Pos.File; !strings.HasSuffix(f, "b.s") {
+			t.Fatalf("first error in %q, want b.s", f)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package asm8
+
+import (
+	"strconv"
+
+	"e8vm.io/e8vm/asm8/ast"
+	"e8vm.io/e8vm/lex8"
+)
+
+type constDecl struct {
+	*ast.Const
+
+	value uint32
+}
+
+// resolveConstDecl parses the value of a constant, which is a 32-bit
+// integer, signed or unsigned.
+func resolveConstDecl(log lex8.Logger, c *ast.Const) *constDecl {
+	ret := new(constDecl)
+	ret.Const = c
+
+	t := c.Value
+	v, e := strconv.ParseInt(t.Lit, 0, 64)
+	if e != nil {
+		log.Errorf(t.Pos, "invalid const value %q: %s", t.Lit, e)
+		return ret
+	}
+	if v > 0xffffffff || v < -0x80000000 {
+		log.Errorf(t.Pos, "const value out of 32-bit range: %s", t.Lit)
+		return ret
+	}
+
+	ret.value = uint32(v)
+	return ret
+}
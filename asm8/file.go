@@ -10,10 +10,13 @@ type file struct {
 
 	funcs   []*funcDecl
 	vars    []*varDecl
+	consts  []*constDecl
 	imports *importDecl
 }
 
-func resolveFile(log lex8.Logger, f *ast.File) *file {
+func resolveFile(
+	log lex8.Logger, f *ast.File, macros map[string]*macroDecl,
+) *file {
 	ret := new(file)
 	ret.File = f
 
@@ -23,12 +26,16 @@ func resolveFile(log lex8.Logger, f *ast.File) *file {
 
 	for _, d := range f.Decls {
 		if d, ok := d.(*ast.Func); ok {
-			ret.funcs = append(ret.funcs, resolveFunc(log, d))
+			ret.funcs = append(ret.funcs, resolveFunc(log, d, macros))
 		}
 
 		if d, ok := d.(*ast.Var); ok {
 			ret.vars = append(ret.vars, resolveVar(log, d))
 		}
+
+		if d, ok := d.(*ast.Const); ok {
+			ret.consts = append(ret.consts, resolveConstDecl(log, d))
+		}
 	}
 
 	return ret
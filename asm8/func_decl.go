@@ -11,11 +11,23 @@ type funcDecl struct {
 	stmts []*funcStmt
 }
 
-func resolveFunc(log lex8.Logger, f *ast.Func) *funcDecl {
+// resolveFunc resolves the statements of a function, where the calls of
+// the macros are expanded.
+func resolveFunc(
+	log lex8.Logger, f *ast.Func, macros map[string]*macroDecl,
+) *funcDecl {
 	ret := new(funcDecl)
 	ret.Func = f
 
 	for _, stmt := range f.Stmts {
+		if m := macros[stmt.Ops[0].Lit]; m != nil {
+			for _, s := range m.expand(log, stmt) {
+				r := resolveFuncStmt(log, s)
+				ret.stmts = append(ret.stmts, r)
+			}
+			continue
+		}
+
 		r := resolveFuncStmt(log, stmt)
 		ret.stmts = append(ret.stmts, r)
 	}
@@ -1,6 +1,7 @@
 package asm8
 
 import (
+	"e8vm.io/e8vm/asm8/parse"
 	"e8vm.io/e8vm/lex8"
 )
 
@@ -12,6 +13,19 @@ var insts = []instResolver{
 	resolveInstSys,
 }
 
+// isInstName checks if a name is the name of an instruction, by checking
+// if any instruction resolver takes it.
+func isInstName(name string) bool {
+	ops := []*lex8.Token{{Type: parse.Operand, Lit: name}}
+	log := lex8.NewErrorList() // errors on arguments are discarded
+	for _, r := range insts {
+		if _, hit := r(log, ops); hit {
+			return true
+		}
+	}
+	return false
+}
+
 func resolveInst(log lex8.Logger, ops []*lex8.Token) *inst {
 	return instResolvers(insts).resolve(log, ops)
 }
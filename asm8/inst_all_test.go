@@ -0,0 +1,21 @@
+package asm8
+
+import (
+	"testing"
+)
+
+func TestIsInstName(t *testing.T) {
+	for _, name := range []string{
+		"add", "sll", "mov", "fadd", "ftoi", "panic", "addi", "lw",
+		"andi", "lui", "bne", "j", "jal", "halt", "ipi",
+	} {
+		if !isInstName(name) {
+			t.Errorf("%q is not an instruction", name)
+		}
+	}
+	for _, name := range []string{"putc", "main", "r1", ".l", "Add"} {
+		if isInstName(name) {
+			t.Errorf("%q is an instruction", name)
+		}
+	}
+}
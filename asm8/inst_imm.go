@@ -20,6 +20,8 @@ var (
 		"lbu": arch8.LBU,
 		"sw":  arch8.SW,
 		"sb":  arch8.SB,
+		"ll":  arch8.LL,
+		"sc":  arch8.SC,
 	}
 
 	// op reg reg imm(unsigned)
@@ -34,6 +34,8 @@ var (
 		"divu": arch8.DIVU,
 		"mod":  arch8.MOD,
 		"modu": arch8.MODU,
+		"cas":  arch8.CAS,
+		"xadd": arch8.XADD,
 	}
 
 	// op reg reg
@@ -48,6 +50,18 @@ var (
 		"fmul": arch8.FMUL,
 		"fdiv": arch8.FDIV,
 		"fint": arch8.FINT,
+		"feq":  arch8.FEQ,
+		"flt":  arch8.FLT,
+		"fle":  arch8.FLE,
+	}
+
+	// op reg reg
+	opFloat2Map = map[string]uint32{
+		"fitof": arch8.FITOF,
+		"fsqrt": arch8.FSQRT,
+		"fabs":  arch8.FABS,
+		"fneg":  arch8.FNEG,
+		"ftoi":  arch8.FTOI,
 	}
 )
 
@@ -124,6 +138,10 @@ func resolveInstReg(log lex8.Logger, ops []*lex8.Token) (*inst, bool) {
 			s2 = resolveReg(log, args[2])
 		}
 		isFloat = 1
+	} else if fn, found = opFloat2Map[opName]; found {
+		// op reg reg
+		argCount(2)
+		isFloat = 1
 	} else {
 		return nil, false
 	}
@@ -8,9 +8,12 @@ import (
 var (
 	// op
 	opSysMap = map[string]uint32{
-		"halt":    arch8.HALT,
-		"syscall": arch8.SYSCALL,
-		"iret":    arch8.IRET,
+		"halt":     arch8.HALT,
+		"syscall":  arch8.SYSCALL,
+		"iret":     arch8.IRET,
+		"fence":    arch8.FENCE,
+		"wfi":      arch8.WFI,
+		"tlbflush": arch8.TLBFLUSH,
 	}
 
 	// op reg
@@ -18,6 +21,8 @@ var (
 		"jruser": arch8.JRUSER,
 		"vtable": arch8.VTABLE,
 		"cpuid":  arch8.CPUID,
+		"ipi":    arch8.IPI,
+		"tlbinv": arch8.TLBINV,
 	}
 )
 
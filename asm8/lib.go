@@ -41,7 +41,7 @@ func (p *lib) Declare(s *sym8.Symbol) {
 
 	switch s.Type {
 	case SymConst:
-		panic("todo")
+		// constants are filled in when compiling, not linked
 	case SymFunc:
 		p.Pkg.DeclareFunc(s.Name())
 	case SymVar:
@@ -0,0 +1,121 @@
+package asm8
+
+import (
+	"e8vm.io/e8vm/asm8/ast"
+	"e8vm.io/e8vm/lex8"
+)
+
+type macroDecl struct {
+	*ast.Macro
+
+	params map[string]int
+}
+
+func resolveMacroDecl(log lex8.Logger, m *ast.Macro) *macroDecl {
+	ret := new(macroDecl)
+	ret.Macro = m
+	ret.params = make(map[string]int)
+
+	for i, t := range m.Params {
+		if _, found := ret.params[t.Lit]; found {
+			log.Errorf(t.Pos, "duplicate macro parameter %q", t.Lit)
+			continue
+		}
+		ret.params[t.Lit] = i
+	}
+
+	for _, stmt := range m.Stmts {
+		op0 := stmt.Ops[0]
+		if isLabelStart(op0.Lit) {
+			// labels would be declared again on each expansion
+			log.Errorf(op0.Pos, "label not allowed in a macro")
+		}
+	}
+
+	return ret
+}
+
+// declName returns the name token and the kind of a declaration that is
+// not a macro, or nil if d is a macro.
+func declName(d interface{}) (*lex8.Token, int) {
+	switch d := d.(type) {
+	case *ast.Func:
+		return d.Name, SymFunc
+	case *ast.Var:
+		return d.Name, SymVar
+	case *ast.Const:
+		return d.Name, SymConst
+	}
+	return nil, SymNone
+}
+
+// resolveMacros collects the macros declared in the files of a package.
+// A macro cannot be named as an instruction, or as another declaration
+// in the package, as the macro would silently replace it.
+func resolveMacros(log lex8.Logger, fs []*ast.File) map[string]*macroDecl {
+	ret := make(map[string]*macroDecl)
+	for _, f := range fs {
+		for _, d := range f.Decls {
+			m, ok := d.(*ast.Macro)
+			if !ok {
+				continue
+			}
+
+			name := m.Name.Lit
+			if isInstName(name) {
+				log.Errorf(m.Name.Pos, "macro %q is an instruction", name)
+				continue
+			}
+			if other, found := ret[name]; found {
+				log.Errorf(m.Name.Pos, "macro %q already declared", name)
+				log.Errorf(other.Name.Pos, "  previously declared here")
+				continue
+			}
+			ret[name] = resolveMacroDecl(log, m)
+		}
+	}
+
+	for _, f := range fs {
+		for _, d := range f.Decls {
+			t, typ := declName(d)
+			if t == nil {
+				continue
+			}
+			if m, found := ret[t.Lit]; found {
+				log.Errorf(m.Name.Pos, "macro %q already declared", t.Lit)
+				log.Errorf(t.Pos, "  here as a %s", symStr(typ))
+			}
+		}
+	}
+	return ret
+}
+
+// expand returns the statements that a macro call expands into. The
+// operands that are parameters are replaced by the arguments of the
+// call. Macro calls are not expanded again inside the expansion.
+func (m *macroDecl) expand(
+	log lex8.Logger, call *ast.FuncStmt,
+) []*ast.FuncStmt {
+	op0 := call.Ops[0]
+	args := call.Ops[1:]
+	if len(args) != len(m.Params) {
+		log.Errorf(op0.Pos, "macro %q needs %d arguments",
+			op0.Lit, len(m.Params),
+		)
+		return nil
+	}
+
+	var ret []*ast.FuncStmt
+	for _, stmt := range m.Stmts {
+		ops := make([]*lex8.Token, len(stmt.Ops))
+		for i, op := range stmt.Ops {
+			if index, found := m.params[op.Lit]; found {
+				ops[i] = args[index]
+			} else {
+				ops[i] = op
+			}
+		}
+		ret = append(ret, &ast.FuncStmt{Ops: ops})
+	}
+	return ret
+}
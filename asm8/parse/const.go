@@ -0,0 +1,28 @@
+package parse
+
+import (
+	"e8vm.io/e8vm/asm8/ast"
+)
+
+func parseConst(p *parser) *ast.Const {
+	ret := new(ast.Const)
+
+	ret.Kw = p.ExpectKeyword("const")
+	ret.Name = p.Expect(Operand)
+
+	if ret.Name != nil {
+		name := ret.Name.Lit
+		if !IsIdent(name) {
+			p.Errorf(ret.Name.Pos, "invalid const name %q", name)
+		}
+	}
+
+	ret.Assign = p.Expect(Assign)
+	ret.Value = p.Expect(Operand)
+	ret.Semi = p.Expect(Semi)
+	if p.skipErrStmt() {
+		return nil
+	}
+
+	return ret
+}
@@ -27,11 +27,17 @@ func parseFile(p *parser) *ast.File {
 				ret.Decls = append(ret.Decls, v)
 			}
 		} else if p.SeeKeyword("const") {
-			// TODO:
-			p.ErrorfHere("const support not implemented yet")
-			p.skipErrStmt()
+			if c := parseConst(p); c != nil {
+				ret.Decls = append(ret.Decls, c)
+			}
+		} else if p.SeeKeyword("macro") {
+			if m := parseMacro(p); m != nil {
+				ret.Decls = append(ret.Decls, m)
+			}
 		} else {
-			p.ErrorfHere("expect top-declaration: func, var or const")
+			p.ErrorfHere(
+				"expect top-declaration: func, var, const or macro",
+			)
 			return nil
 		}
 
@@ -0,0 +1,63 @@
+package parse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"e8vm.io/e8vm/asm8/ast"
+)
+
+func pfile(s string) {
+	rc := ioutil.NopCloser(strings.NewReader(s))
+	f, errs := File("t.s8", rc)
+	if errs != nil {
+		for _, e := range errs {
+			fmt.Println(e)
+		}
+		return
+	}
+
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.Const:
+			fmt.Printf("const %s = %s\n", d.Name.Lit, d.Value.Lit)
+		case *ast.Macro:
+			fmt.Printf("macro %s", d.Name.Lit)
+			for _, p := range d.Params {
+				fmt.Printf(" %s", p.Lit)
+			}
+			fmt.Printf(" (%d stmts)\n", len(d.Stmts))
+		case *ast.Func:
+			fmt.Printf("func %s (%d stmts)\n", d.Name.Lit, len(d.Stmts))
+		}
+	}
+}
+
+func ExampleFile_const() {
+	pfile(`
+	const Serial = 0x2000
+	const minus = -1
+
+	macro putc r {
+		ori r2 r0 Serial
+		sw r r2
+	}
+
+	func main {
+		putc r1
+	}`)
+	// Output:
+	// const Serial = 0x2000
+	// const minus = -1
+	// macro putc r (2 stmts)
+	// func main (1 stmts)
+}
+
+func ExampleFile_badConst() {
+	pfile("const Serial 0x2000\n")
+	pfile("const 3x = 1\n")
+	// Output:
+	// t.s8:1: expect '=', got operand
+	// t.s8:1: invalid const name "3x"
+}
@@ -22,6 +22,9 @@ func lexAsm8(x *lex8.Lexer) *lex8.Token {
 	case '}':
 		x.Next()
 		return x.MakeToken(Rbrace)
+	case '=':
+		x.Next()
+		return x.MakeToken(Assign)
 	case '/':
 		x.Next()
 		return lex8.LexComment(x)
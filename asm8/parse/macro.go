@@ -0,0 +1,46 @@
+package parse
+
+import (
+	"e8vm.io/e8vm/asm8/ast"
+	"e8vm.io/e8vm/lex8"
+)
+
+func parseMacro(p *parser) *ast.Macro {
+	ret := new(ast.Macro)
+
+	ret.Kw = p.ExpectKeyword("macro")
+	ret.Name = p.Expect(Operand)
+
+	if ret.Name != nil {
+		name := ret.Name.Lit
+		if !IsIdent(name) {
+			p.Errorf(ret.Name.Pos, "invalid macro name %q", name)
+		}
+	}
+
+	for p.See(Operand) {
+		t := p.Shift()
+		if !IsIdent(t.Lit) {
+			p.Errorf(t.Pos, "invalid macro parameter %q", t.Lit)
+		}
+		ret.Params = append(ret.Params, t)
+	}
+
+	ret.Lbrace = p.Expect(Lbrace)
+	if p.skipErrStmt() { // header broken
+		return ret
+	}
+
+	for !(p.See(Rbrace) || p.See(lex8.EOF)) {
+		stmt := parseFuncStmt(p)
+		if stmt != nil {
+			ret.Stmts = append(ret.Stmts, stmt)
+		}
+	}
+
+	ret.Rbrace = p.Expect(Rbrace)
+	ret.Semi = p.Expect(Semi)
+	p.skipErrStmt()
+
+	return ret
+}
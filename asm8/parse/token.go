@@ -13,6 +13,7 @@ const (
 	Rbrace
 	Endl
 	Semi
+	Assign
 )
 
 // Types provides a type name querier
@@ -29,6 +30,7 @@ var Types = func() *lex8.Types {
 	o(String, "string")
 	o(Semi, "';'")
 	o(Endl, "end-line")
+	o(Assign, "'='")
 
 	return ret
 }()
@@ -1,6 +1,8 @@
 package asm8
 
 import (
+	"sort"
+
 	"e8vm.io/e8vm/asm8/ast"
 	"e8vm.io/e8vm/asm8/parse"
 	"e8vm.io/e8vm/build8"
@@ -34,9 +36,21 @@ func resolvePkg(p string, src map[string]*build8.File) (*pkg, []*lex8.Error) {
 		return nil, parseErrs
 	}
 
+	// macros can be used in all files of the package
+	var names []string
+	for name := range asts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var astFiles []*ast.File
+	for _, name := range names {
+		astFiles = append(astFiles, asts[name])
+	}
+	macros := resolveMacros(log, astFiles)
+
 	for name, astFile := range asts {
 		// then resolve the file
-		file := resolveFile(log, astFile)
+		file := resolveFile(log, astFile, macros)
 		ret.files = append(ret.files, file)
 
 		// enforce import policy
@@ -62,12 +62,30 @@ func (b *Builder) prepare(p string) (*pkg, []*lex8.Error) {
 	return pkg, nil
 }
 
+// link links a test image, which is compressed as it is written for
+// every package.
 func (b *Builder) link(p *link8.Pkg, out io.Writer, main string) error {
 	job := link8.NewJob(p, main)
 	job.InitPC = b.InitPC
+	job.Compress = true
 	return job.Link(out)
 }
 
+// linkMain links the main image and also writes the linker map into the
+// package's "map" log.
+func (b *Builder) linkMain(p *pkg, out io.Writer, main string) error {
+	job := link8.NewJob(p.compiled.Lib(), main)
+	job.InitPC = b.InitPC
+
+	m := b.home.CreateLog(p.path, "map")
+	job.Map = m
+	err := job.Link(out)
+	if e := m.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
 func (b *Builder) buildImports(p *pkg, forTest bool) []*lex8.Error {
 	for _, imp := range p.imports {
 		built, es := b.build(imp.Path, forTest)
@@ -86,7 +104,7 @@ func (b *Builder) buildMain(p *pkg) []*lex8.Error {
 		log := lex8.NewErrorList()
 
 		fout := b.home.CreateBin(p.path)
-		lex8.LogError(log, b.link(lib, fout, main))
+		lex8.LogError(log, b.linkMain(p, fout, main))
 		lex8.LogError(log, fout.Close())
 
 		if es := log.Errs(); es != nil {
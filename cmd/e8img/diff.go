@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"e8vm.io/e8vm/e8"
+)
+
+// diffSection prints the differences of two sections, and returns true
+// if they differ.
+func diffSection(w io.Writer, i int, s1, s2 *e8.Section) bool {
+	var diffs []string
+	if s1.Type != s2.Type {
+		diffs = append(diffs, fmt.Sprintf("type %s != %s",
+			e8.TypeName(s1.Type), e8.TypeName(s2.Type),
+		))
+	}
+	if s1.Flag != s2.Flag {
+		diffs = append(diffs, fmt.Sprintf("flag %s != %s",
+			flagString(s1.Flag), flagString(s2.Flag),
+		))
+	}
+	if s1.Addr != s2.Addr {
+		diffs = append(diffs, fmt.Sprintf("addr %08x != %08x",
+			s1.Addr, s2.Addr,
+		))
+	}
+	if s1.Size != s2.Size {
+		diffs = append(diffs, fmt.Sprintf("size %d != %d",
+			s1.Size, s2.Size,
+		))
+	}
+	for j := 0; j < len(s1.Bytes) && j < len(s2.Bytes); j++ {
+		if s1.Bytes[j] != s2.Bytes[j] {
+			diffs = append(diffs, fmt.Sprintf(
+				"first different byte at offset %d", j,
+			))
+			break
+		}
+	}
+
+	for _, d := range diffs {
+		fmt.Fprintf(w, "section %d: %s\n", i, d)
+	}
+	return len(diffs) > 0
+}
+
+// diffImages prints the differences of two images, named name1 and
+// name2, and returns true if they differ.
+func diffImages(w io.Writer, name1, name2 string, img1, img2 *e8.Image) bool {
+	differ := false
+	if img1.HasEntry != img2.HasEntry || img1.Entry != img2.Entry {
+		fmt.Fprintf(w, "entry %08x != %08x\n", img1.Entry, img2.Entry)
+		differ = true
+	}
+
+	n1, n2 := len(img1.Sections), len(img2.Sections)
+	for i := 0; i < n1 && i < n2; i++ {
+		if diffSection(w, i, img1.Sections[i], img2.Sections[i]) {
+			differ = true
+		}
+	}
+	for i := n2; i < n1; i++ {
+		fmt.Fprintf(w, "section %d: only in %s\n", i, name1)
+		differ = true
+	}
+	for i := n1; i < n2; i++ {
+		fmt.Fprintf(w, "section %d: only in %s\n", i, name2)
+		differ = true
+	}
+	return differ
+}
+
+func diff(w io.Writer, args []string) error {
+	img1, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	img2, err := open(args[1])
+	if err != nil {
+		return err
+	}
+
+	if diffImages(w, args[0], args[1], img1, img2) {
+		return errors.New("images differ")
+	}
+	return nil
+}
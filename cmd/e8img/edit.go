@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+
+	"e8vm.io/e8vm/e8"
+)
+
+func section(img *e8.Image, index string) (*e8.Section, error) {
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= len(img.Sections) {
+		return nil, fmt.Errorf("section %d out of range", i)
+	}
+	return img.Sections[i], nil
+}
+
+// bytesSection returns the section of the index that has bytes.
+func bytesSection(img *e8.Image, index string) (*e8.Section, error) {
+	s, err := section(img, index)
+	if err != nil {
+		return nil, err
+	}
+	if s.Type == e8.Zeros {
+		return nil, fmt.Errorf("zeros section has no bytes")
+	}
+	return s, nil
+}
+
+func extract(_ io.Writer, args []string) error {
+	img, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	s, err := bytesSection(img, args[1])
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(args[2], s.Bytes, 0644)
+}
+
+func replace(_ io.Writer, args []string) error {
+	img, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	s, err := bytesSection(img, args[1])
+	if err != nil {
+		return err
+	}
+	bs, err := ioutil.ReadFile(args[2])
+	if err != nil {
+		return err
+	}
+	s.Bytes = bs
+	return create(args[3], img)
+}
+
+// stripSections returns the sections that are not of the types.
+func stripSections(secs []*e8.Section, types ...uint8) []*e8.Section {
+	var ret []*e8.Section
+	for _, s := range secs {
+		keep := true
+		for _, t := range types {
+			if s.Type == t {
+				keep = false
+			}
+		}
+		if keep {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func stripTypes(args []string, types ...uint8) error {
+	img, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	img.Sections = stripSections(img.Sections, types...)
+	return create(args[1], img)
+}
+
+func strip(_ io.Writer, args []string) error {
+	return stripTypes(args, e8.DebugInfo, e8.Comment)
+}
+
+func stripAll(_ io.Writer, args []string) error {
+	return stripTypes(args, e8.DebugInfo, e8.Comment, e8.Symbols)
+}
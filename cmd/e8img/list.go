@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"e8vm.io/e8vm/dasm8"
+	"e8vm.io/e8vm/e8"
+)
+
+func flagString(f uint8) string {
+	ret := []byte("---")
+	if f&e8.FlagReadonly != 0 {
+		ret[0] = 'r'
+	}
+	if f&e8.FlagExec != 0 {
+		ret[1] = 'x'
+	}
+	if f&e8.FlagCompressed != 0 {
+		ret[2] = 'z'
+	}
+	return string(ret)
+}
+
+func listSections(w io.Writer, args []string) error {
+	img, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "version %d", img.Version)
+	if img.HasEntry {
+		fmt.Fprintf(w, ", entry %08x", img.Entry)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "idx type       flag addr     size")
+	for i, s := range img.Sections {
+		fmt.Fprintf(w, "%3d %-10s %-4s %08x %d\n",
+			i, e8.TypeName(s.Type), flagString(s.Flag), s.Addr, s.Size,
+		)
+	}
+	return nil
+}
+
+func listSymbols(w io.Writer, args []string) error {
+	img, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	syms, err := e8.FindSymbols(img.Sections)
+	if err != nil {
+		return err
+	}
+	for _, s := range syms {
+		typ := "func"
+		if s.Type == e8.SymVar {
+			typ = "var"
+		}
+		fmt.Fprintf(w, "%08x %d %s %s\n", s.Addr, s.Size, typ, s.Name)
+	}
+	return nil
+}
+
+func listLines(w io.Writer, args []string) error {
+	img, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	ents, err := e8.FindLines(img.Sections)
+	if err != nil {
+		return err
+	}
+	for _, e := range ents {
+		if e.File == "" {
+			fmt.Fprintf(w, "%08x -\n", e.Addr)
+		} else {
+			fmt.Fprintf(w, "%08x %s\n", e.Addr, e)
+		}
+	}
+	return nil
+}
+
+func dasm(w io.Writer, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return dasm8.DumpImage(f, w)
+}
@@ -0,0 +1,104 @@
+// Command e8img inspects and edits e8 images.
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"e8vm.io/e8vm/e8"
+)
+
+type command struct {
+	name  string
+	args  string
+	help  string
+	nargs int
+	run   func(w io.Writer, args []string) error
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"sections", "image", "list the sections", 1, listSections},
+		{"symbols", "image", "list the symbols", 1, listSymbols},
+		{"lines", "image", "list the source line entries", 1, listLines},
+		{"dasm", "image", "disassemble the image", 1, dasm},
+		{
+			"extract", "image index out",
+			"write the bytes of a section into a file", 3, extract,
+		},
+		{
+			"replace", "image index in out",
+			"replace the bytes of a section with a file", 4, replace,
+		},
+		{
+			"strip", "image out",
+			"remove the debug info and the comments", 2, strip,
+		},
+		{
+			"stripall", "image out",
+			"also remove the symbols", 2, stripAll,
+		},
+		{"diff", "image1 image2", "compare two images", 2, diff},
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: e8img <command> [args]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %-19s %s\n", c.name, c.args, c.help)
+	}
+	os.Exit(2)
+}
+
+func open(path string) (*e8.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := e8.ReadImage(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return img, nil
+}
+
+func create(path string, img *e8.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := e8.WriteImage(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	name, args := os.Args[1], os.Args[2:]
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		if len(args) != c.nargs {
+			log.Fatalf("usage: e8img %s %s", c.name, c.args)
+		}
+		if err := c.run(os.Stdout, args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	usage()
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"e8vm.io/e8vm/e8"
+)
+
+func testImage() *e8.Image {
+	code := []byte{0, 0, 0, 0x40} // halt
+	syms := e8.EncodeSymbols([]*e8.Symbol{
+		{Type: e8.SymFunc, Name: "main.main", Addr: 0x8000, Size: 4},
+	})
+	lines := e8.EncodeLines([]*e8.LineEntry{
+		{Addr: 0x8000, File: "main.g", Line: 3, Col: 2},
+	})
+	sec := func(t, flag uint8, addr uint32, bs []byte) *e8.Section {
+		return &e8.Section{
+			Header: &e8.Header{Type: t, Flag: flag, Addr: addr},
+			Bytes:  bs,
+		}
+	}
+	return &e8.Image{
+		Entry:    0x8000,
+		HasEntry: true,
+		Sections: []*e8.Section{
+			sec(e8.Code, e8.FlagReadonly|e8.FlagExec, 0x8000, code),
+			sec(e8.Data, e8.FlagCompressed, 0x9000, []byte("hello")),
+			{Header: &e8.Header{Type: e8.Zeros, Addr: 0xa000, Size: 64}},
+			sec(e8.Symbols, 0, 0, syms),
+			sec(e8.DebugInfo, 0, 0, lines),
+			sec(e8.Comment, 0, 0, []byte("built for testing")),
+		},
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "e8img")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func runCommand(t *testing.T, args ...string) string {
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+		out := new(bytes.Buffer)
+		if err := c.run(out, args[1:]); err != nil {
+			t.Fatalf("%s: %s", args[0], err)
+		}
+		return out.String()
+	}
+	t.Fatalf("command %q not found", args[0])
+	return ""
+}
+
+func TestCommands(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	p := func(name string) string { return filepath.Join(dir, name) }
+
+	if err := create(p("a.e8"), testImage()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := runCommand(t, "sections", p("a.e8"))
+	want := strings.Join([]string{
+		"version 1, entry 00008000",
+		"idx type       flag addr     size",
+		"  0 code       rx-  00008000 4",
+		"  1 data       --z  00009000 5",
+		"  2 zeros      ---  0000a000 64",
+		"  3 symbols    ---  00000000 22",
+		"  4 debuginfo  ---  00000000 30",
+		"  5 comment    ---  00000000 17",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("sections got:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = runCommand(t, "symbols", p("a.e8"))
+	if want := "00008000 4 func main.main\n"; got != want {
+		t.Errorf("symbols got %q, want %q", got, want)
+	}
+	got = runCommand(t, "lines", p("a.e8"))
+	if want := "00008000 main.g:3:2\n"; got != want {
+		t.Errorf("lines got %q, want %q", got, want)
+	}
+	if got = runCommand(t, "dasm", p("a.e8")); !strings.Contains(got, "halt") {
+		t.Errorf("dasm got:\n%s", got)
+	}
+
+	runCommand(t, "extract", p("a.e8"), "1", p("data"))
+	bs, err := ioutil.ReadFile(p("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != "hello" {
+		t.Errorf("extracted %q", bs)
+	}
+
+	if err := ioutil.WriteFile(p("data"), []byte("bye"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runCommand(t, "replace", p("a.e8"), "1", p("data"), p("b.e8"))
+	img, err := open(p("b.e8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := img.Sections[1]; string(s.Bytes) != "bye" || s.Size != 3 {
+		t.Errorf("replaced section has %q", s.Bytes)
+	}
+
+	out := new(bytes.Buffer)
+	if err := diff(out, []string{p("a.e8"), p("b.e8")}); err == nil {
+		t.Error("diff of different images returned no error")
+	}
+	want = "section 1: size 5 != 3\n" +
+		"section 1: first different byte at offset 0\n"
+	if got = out.String(); got != want {
+		t.Errorf("diff got %q, want %q", got, want)
+	}
+	if got := runCommand(t, "diff", p("a.e8"), p("a.e8")); got != "" {
+		t.Errorf("diff of the same image got %q", got)
+	}
+
+	for _, test := range []struct {
+		cmd   string
+		types []uint8
+	}{
+		{"strip", []uint8{e8.Code, e8.Data, e8.Zeros, e8.Symbols}},
+		{"stripall", []uint8{e8.Code, e8.Data, e8.Zeros}},
+	} {
+		runCommand(t, test.cmd, p("a.e8"), p("c.e8"))
+		img, err := open(p("c.e8"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var types []uint8
+		for _, s := range img.Sections {
+			types = append(types, s.Type)
+		}
+		if !bytes.Equal(types, test.types) {
+			t.Errorf("%s: got section types %v", test.cmd, types)
+		}
+		if !img.HasEntry || img.Entry != 0x8000 {
+			t.Errorf("%s: entry lost", test.cmd)
+		}
+	}
+}
+
+func TestDiffImages(t *testing.T) {
+	img1, img2 := testImage(), testImage()
+	img2.Entry = 0x8004
+	img2.Sections[0].Flag = e8.FlagExec
+	img2.Sections[2].Addr = 0xb000
+	img2.Sections = img2.Sections[:4]
+
+	out := new(bytes.Buffer)
+	if !diffImages(out, "a", "b", img1, img2) {
+		t.Error("images not different")
+	}
+	want := strings.Join([]string{
+		"entry 00008000 != 00008004",
+		"section 0: flag rx- != -x-",
+		"section 2: addr 0000a000 != 0000b000",
+		"section 4: only in a",
+		"section 5: only in a",
+		"",
+	}, "\n")
+	if got := out.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStripSections(t *testing.T) {
+	secs := testImage().Sections
+	got := stripSections(secs, e8.Comment, e8.Code)
+	if len(got) != 4 || got[0] != secs[1] || got[3] != secs[4] {
+		t.Errorf("got %d sections", len(got))
+	}
+	if got := stripSections(secs); len(got) != len(secs) {
+		t.Errorf("stripped %d sections with no types", len(secs)-len(got))
+	}
+}
+
+func TestSectionIndex(t *testing.T) {
+	img := testImage()
+	for _, index := range []string{"-1", "6", "x", "2"} {
+		if _, err := bytesSection(img, index); err == nil {
+			t.Errorf("section %q accepted", index)
+		}
+	}
+}
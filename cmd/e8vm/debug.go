@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/dasm8"
+)
+
+const debugHelp = `commands:
+  c              continue until a stop
+  s [n]          tick the machine n times, default 1
+  si [core]      step one instruction on a core
+  b addr         add a breakpoint
+  d addr         delete a breakpoint
+  bl             list breakpoints
+  w addr         watch writes on a virtual address
+  wp addr        watch writes on a physical address
+  uw addr        unwatch a virtual address
+  uwp addr       unwatch a physical address
+  r              print registers of all cores
+  x addr [n]     dump n words of virtual memory of the core
+  core n         switch the current core
+  q              quit
+`
+
+type debugger struct {
+	m    *arch8.Machine
+	core int
+
+	ncycle int
+	exp    *arch8.CoreExcep
+}
+
+func parseAddr(s string) (uint32, error) {
+	ret, err := strconv.ParseUint(s, 0, 32)
+	return uint32(ret), err
+}
+
+// printInst prints the instruction that the current core is about to
+// execute.
+func (d *debugger) printInst() {
+	pc := d.m.Reg(d.core, arch8.PC)
+	in, err := d.m.ReadVirtWord(d.core, pc)
+	if err != nil {
+		fmt.Printf("%08x: %s\n", pc, err)
+		return
+	}
+	fmt.Println(dasm8.NewLine(pc, in))
+}
+
+func (d *debugger) stopped(n int, e *arch8.CoreExcep) {
+	d.ncycle += n
+	d.exp = e
+	if e != nil {
+		d.core = e.Core
+		fmt.Printf("core %d: %s\n", e.Core, e)
+	}
+	d.printInst()
+}
+
+func (d *debugger) cmd(args []string) (bool, error) {
+	arg := func(i int) (uint32, error) {
+		if i >= len(args) {
+			return 0, fmt.Errorf("missing argument")
+		}
+		return parseAddr(args[i])
+	}
+
+	switch args[0] {
+	case "c":
+		n, e := d.m.Run(*ncycle)
+		d.stopped(n, e)
+	case "s":
+		n := uint32(1)
+		if len(args) > 1 {
+			var err error
+			if n, err = arg(1); err != nil {
+				return false, err
+			}
+		}
+		if n == 0 { // Run(0) would run without a limit
+			return false, fmt.Errorf("tick count must be positive")
+		}
+		cycles, e := d.m.Run(int(n))
+		d.stopped(cycles, e)
+	case "si":
+		core := uint32(d.core)
+		if len(args) > 1 {
+			var err error
+			if core, err = arg(1); err != nil {
+				return false, err
+			}
+		}
+		if int(core) >= d.m.Ncore() {
+			return false, fmt.Errorf("core %d out of range", core)
+		}
+		d.core = int(core)
+		d.stopped(1, d.m.StepCore(d.core))
+	case "b", "d", "w", "wp", "uw", "uwp":
+		addr, err := arg(1)
+		if err != nil {
+			return false, err
+		}
+		switch args[0] {
+		case "b":
+			d.m.AddBreakpoint(addr)
+		case "d":
+			d.m.RemoveBreakpoint(addr)
+		case "w":
+			d.m.AddWatchpoint(addr, true)
+		case "wp":
+			d.m.AddWatchpoint(addr, false)
+		case "uw":
+			d.m.RemoveWatchpoint(addr, true)
+		case "uwp":
+			d.m.RemoveWatchpoint(addr, false)
+		}
+	case "bl":
+		for _, pc := range d.m.Breakpoints() {
+			fmt.Printf("%08x\n", pc)
+		}
+	case "r":
+		d.m.PrintCoreStatus()
+	case "x":
+		addr, err := arg(1)
+		if err != nil {
+			return false, err
+		}
+		n := uint32(1)
+		if len(args) > 2 {
+			if n, err = arg(2); err != nil {
+				return false, err
+			}
+		}
+		for i := uint32(0); i < n; i++ {
+			a := addr + i*4
+			w, err := d.m.ReadVirtWord(d.core, a)
+			if err != nil {
+				return false, err
+			}
+			fmt.Printf("%08x: %08x\n", a, w)
+		}
+	case "core":
+		core, err := arg(1)
+		if err != nil {
+			return false, err
+		}
+		if int(core) >= d.m.Ncore() {
+			return false, fmt.Errorf("core %d out of range", core)
+		}
+		d.core = int(core)
+		d.printInst()
+	case "q":
+		return true, nil
+	case "h", "help":
+		fmt.Print(debugHelp)
+	default:
+		return false, fmt.Errorf("unknown command %q, try help", args[0])
+	}
+
+	return false, nil
+}
+
+// debug runs the machine interactively with commands read from r. It
+// returns the number of cycles executed and the last exception met.
+func debug(m *arch8.Machine, r io.Reader) (int, error) {
+	d := &debugger{m: m}
+	d.printInst()
+
+	s := bufio.NewScanner(r)
+	for {
+		fmt.Print("(e8db) ")
+		if !s.Scan() {
+			break
+		}
+
+		args := strings.Fields(s.Text())
+		if len(args) == 0 {
+			continue
+		}
+		quit, err := d.cmd(args)
+		if err != nil {
+			fmt.Println(err)
+		}
+		if quit {
+			break
+		}
+	}
+
+	if d.exp == nil {
+		return d.ncycle, nil
+	}
+	return d.ncycle, d.exp
+}
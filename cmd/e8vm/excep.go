@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/dasm8"
+	"e8vm.io/e8vm/e8"
+	"e8vm.io/e8vm/prof8"
+)
+
+// symString returns the function symbol and the offset of a pc, and the
+// source position, or an empty string if unknown.
+func symString(syms *prof8.SymTable, lines *e8.LineTable, pc uint32) string {
+	ret := ""
+	if sym := syms.Lookup(pc); sym != nil {
+		ret = fmt.Sprintf(" <%s+0x%x>", sym.Name, pc-sym.Addr)
+	}
+	if pos := lines.Lookup(pc); pos != nil {
+		ret += " at " + pos.String()
+	}
+	return ret
+}
+
+// printExcep prints the report of an exception thrown out of a core,
+// with the backtrace if the symbols are known.
+func printExcep(
+	m *arch8.Machine, e *arch8.CoreExcep,
+	syms *prof8.SymTable, lines *e8.LineTable,
+) {
+	r := m.ExcepReport(e)
+	fmt.Printf("core %d: %s\n", r.Core, r.Excep)
+	fmt.Printf("pc = 0x%08x%s, ring = %d\n",
+		r.PC, symString(syms, lines, r.PC), r.Ring,
+	)
+	if r.InstOK {
+		fmt.Println(dasm8.NewLine(r.PC, r.Inst))
+	}
+	if r.HasAddr {
+		fmt.Printf("address = 0x%08x", r.VirtAddr)
+		if r.HasPhyAddr {
+			fmt.Printf(" (physical 0x%08x)", r.PhyAddr)
+		}
+		fmt.Println()
+	}
+
+	names := []string{"r0", "r1", "r2", "r3", "r4", "sp", "ret", "pc"}
+	for i, name := range names {
+		fmt.Printf("%4s = 0x%08x", name, r.Regs[i])
+		if i%4 == 3 {
+			fmt.Println()
+		}
+	}
+
+	if syms == nil {
+		return
+	}
+	fmt.Println("backtrace:")
+	for _, f := range prof8.Backtrace(m, r.Core, syms) {
+		fmt.Printf("  0x%08x%s\n", f.PC, symString(syms, lines, f.PC))
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log"
+	"net"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/gdb8"
+)
+
+// serveGDB waits for one debugger to connect on addr, and serves the
+// remote serial protocol until it detaches.
+func serveGDB(m *arch8.Machine, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer lis.Close()
+
+	log.Printf("waiting for gdb on %s", lis.Addr())
+	conn, err := lis.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return gdb8.NewStub(m).Serve(conn)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"e8vm.io/e8vm/arch8"
+)
+
+// setupInputLog starts recording or replaying the external input of the
+// machine as the flags specify. The record file is left open until the
+// program exits.
+func setupInputLog(m *arch8.Machine) error {
+	if *recordFile != "" && *replayFile != "" {
+		return errors.New("cannot record and replay at the same time")
+	}
+
+	if *recordFile != "" {
+		f, err := os.Create(*recordFile)
+		if err != nil {
+			return err
+		}
+		return m.Record(f)
+	}
+
+	if *replayFile != "" {
+		f, err := os.Open(*replayFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return m.Replay(f)
+	}
+
+	return nil
+}
@@ -16,15 +16,45 @@ var (
 	doDasm      = flag.Bool("d", false, "do dump")
 	ncycle      = flag.Int("n", 100000, "max cycles to execute")
 	memSize     = flag.Int("m", 0, "memory size; 0 for full 4GB")
+	ncore       = flag.Int("ncore", 1, "number of cores")
+	coreHalt    = flag.Bool("corehalt", false, "halt only the halting core")
+	slowMode    = flag.Bool("slow", false, "use the slow interpreter")
+	costModel   = flag.Bool("cost", false, "use the default cycle cost model")
+	schedPolicy = flag.String("sched", "", "rr, random or weight:w,..")
 	printStatus = flag.Bool("s", false, "print status after execution")
 	bootArg     = flag.Uint("arg", 0, "boot argument, a uint32 number")
 	romRoot     = flag.String("rom", "", "rom root path")
+	diskFile    = flag.String("disk", "", "disk image file to attach")
+	nicAddr     = flag.String("nic", "", "nic backend, unix:sock or pipe:i,o")
 	randSeed    = flag.Int64("seed", 0, "random seed, 0 for using the time")
+	doDebug     = flag.Bool("debug", false, "run in the interactive debugger")
+	gdbAddr     = flag.String("gdb", "", "serve gdb remote protocol on address")
+	recordFile  = flag.String("record", "", "record external input into file")
+	replayFile  = flag.String("replay", "", "replay external input from file")
+	traceFile   = flag.String("trace", "", "write instruction trace into file")
+	btraceFile  = flag.String("btrace", "", "write binary trace into file")
+	profFile    = flag.String("prof", "", "write folded profile into file")
+	profLines   = flag.String("proflines", "", "write line profile into file")
+	profFlat    = flag.String("profflat", "", "write flat profile into file")
+	profPeriod  = flag.Int("profperiod", 100, "ticks between profile samples")
+	mapFile     = flag.String("map", "", "linker map for symbolizing")
 )
 
 func run(bs []byte) (int, error) {
 	// create a single core machine
-	m := arch8.NewMachine(uint32(*memSize), 1)
+	if *ncore < 1 || *ncore > 32 {
+		log.Fatalf("invalid number of cores: %d", *ncore)
+	}
+	m := arch8.NewMachine(uint32(*memSize), *ncore)
+	defer m.Close()
+	m.SetCoreHalt(*coreHalt)
+	m.SetFast(!*slowMode)
+	if *costModel {
+		m.SetCostModel(arch8.DefaultCostModel())
+	}
+	if err := setupScheduler(m); err != nil {
+		return 0, err
+	}
 	if err := m.LoadImageBytes(bs); err != nil {
 		return 0, err
 	}
@@ -39,9 +69,41 @@ func run(bs []byte) (int, error) {
 	if *romRoot != "" {
 		m.MountROM(*romRoot)
 	}
+	if *diskFile != "" {
+		if err := m.AttachDisk(*diskFile); err != nil {
+			return 0, err
+		}
+	}
 	if *randSeed != 0 {
 		m.RandSeed(*randSeed)
 	}
+	if err := setupNIC(m); err != nil {
+		return 0, err
+	}
+	if err := setupInputLog(m); err != nil {
+		return 0, err
+	}
+	syms, lines, err := loadDebugInfo(bs)
+	if err != nil {
+		return 0, err
+	}
+	closeTrace, err := setupTracer(m, lines)
+	if err != nil {
+		return 0, err
+	}
+	defer closeTrace()
+	writeProfile, err := setupProfile(m, syms, lines)
+	if err != nil {
+		return 0, err
+	}
+	defer writeProfile()
+
+	if *doDebug {
+		return debug(m, os.Stdin)
+	}
+	if *gdbAddr != "" {
+		return 0, serveGDB(m, *gdbAddr)
+	}
 
 	ret, exp := m.Run(*ncycle)
 	if *printStatus {
@@ -51,6 +113,9 @@ func run(bs []byte) (int, error) {
 	if exp == nil {
 		return ret, nil
 	}
+	if !arch8.IsHalt(exp) {
+		printExcep(m, exp, syms, lines)
+	}
 	return ret, exp
 }
 
@@ -80,7 +145,8 @@ func main() {
 		n, e := run(bs)
 		fmt.Printf("(%d cycles)\n", n)
 		if e != nil {
-			if !arch8.IsHalt(e) {
+			// exceptions from the guest are reported already
+			if _, ok := e.(*arch8.CoreExcep); !ok {
 				fmt.Println(e)
 			}
 		} else {
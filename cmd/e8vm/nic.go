@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"e8vm.io/e8vm/arch8"
+)
+
+// setupNIC attaches a nic as the flag specifies, either "unix:path" for
+// dialing a unix socket, or "pipe:in,out" for a pair of named pipes. The
+// files are left open until the program exits.
+func setupNIC(m *arch8.Machine) error {
+	if *nicAddr == "" {
+		return nil
+	}
+
+	var b arch8.NetBackend
+	switch {
+	case strings.HasPrefix(*nicAddr, "unix:"):
+		conn, err := net.Dial("unix", strings.TrimPrefix(*nicAddr, "unix:"))
+		if err != nil {
+			return err
+		}
+		b = arch8.NewNetStream(conn, conn)
+	case strings.HasPrefix(*nicAddr, "pipe:"):
+		paths := strings.Split(strings.TrimPrefix(*nicAddr, "pipe:"), ",")
+		if len(paths) != 2 {
+			return fmt.Errorf("invalid pipe pair: %q", *nicAddr)
+		}
+		in, err := os.Open(paths[0])
+		if err != nil {
+			return err
+		}
+		out, err := os.OpenFile(paths[1], os.O_WRONLY, 0)
+		if err != nil {
+			in.Close()
+			return err
+		}
+		b = arch8.NewNetStream(in, out)
+	default:
+		return fmt.Errorf("invalid nic backend: %q", *nicAddr)
+	}
+
+	return m.AttachNIC(b)
+}
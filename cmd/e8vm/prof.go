@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"os"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/e8"
+	"e8vm.io/e8vm/prof8"
+)
+
+// loadDebugInfo loads the function symbols from the linker map, or from
+// the Symbols section of the image if there is no map, and the line
+// table from the DebugInfo section of the image. Each is nil if unknown.
+func loadDebugInfo(image []byte) (
+	*prof8.SymTable, *e8.LineTable, error,
+) {
+	secs, err := e8.Read(bytes.NewReader(image))
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := e8.ImageLines(secs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var syms *prof8.SymTable
+	if *mapFile != "" {
+		syms, err = prof8.OpenMap(*mapFile)
+	} else {
+		syms, err = prof8.ImageSymbols(secs)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return syms, lines, nil
+}
+
+func writeProfileFile(path string, write func(w io.Writer) error) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	defer f.Close()
+
+	if err := write(f); err != nil {
+		log.Print(err)
+	}
+}
+
+// setupProfile starts sampling the guest program as the flags specify.
+// It returns a function that writes out the profile.
+func setupProfile(
+	m *arch8.Machine, syms *prof8.SymTable, lines *e8.LineTable,
+) (func(), error) {
+	if *profFile == "" && *profLines == "" && *profFlat == "" {
+		return func() {}, nil
+	}
+	if *profPeriod <= 0 {
+		return nil, errors.New("profile period must be positive")
+	}
+
+	p := prof8.NewStackProfile(m, syms)
+	m.SetSampler(p, *profPeriod)
+
+	return func() {
+		if *profFile != "" {
+			writeProfileFile(*profFile, func(w io.Writer) error {
+				return p.WriteFolded(w, syms)
+			})
+		}
+		if *profFlat != "" {
+			writeProfileFile(*profFlat, func(w io.Writer) error {
+				return p.WriteFlat(w, syms)
+			})
+		}
+		if *profLines != "" {
+			writeProfileFile(*profLines, func(w io.Writer) error {
+				return p.WriteLines(w, lines)
+			})
+		}
+	}, nil
+}
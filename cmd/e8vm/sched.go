@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"e8vm.io/e8vm/arch8"
+)
+
+// setupScheduler sets the interleaving policy of the cores as the flag
+// specifies: "rr", "random", or "weight:" followed by the weights of
+// the cores separated by commas. The random policy uses the random seed.
+func setupScheduler(m *arch8.Machine) error {
+	switch {
+	case *schedPolicy == "":
+		return nil
+	case *schedPolicy == "rr":
+		m.SetScheduler(new(arch8.RoundRobin))
+	case *schedPolicy == "random":
+		seed := *randSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		m.SetScheduler(arch8.NewRandomScheduler(seed))
+	case strings.HasPrefix(*schedPolicy, "weight:"):
+		var weights []int
+		s := strings.TrimPrefix(*schedPolicy, "weight:")
+		for _, f := range strings.Split(s, ",") {
+			w, err := strconv.Atoi(f)
+			if err != nil || w < 0 {
+				return fmt.Errorf("invalid weight: %q", f)
+			}
+			weights = append(weights, w)
+		}
+		m.SetScheduler(arch8.NewWeightedScheduler(weights))
+	default:
+		return fmt.Errorf("invalid scheduling policy: %q", *schedPolicy)
+	}
+	return nil
+}
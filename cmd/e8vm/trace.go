@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"log"
+	"os"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/dasm8"
+	"e8vm.io/e8vm/e8"
+)
+
+// setupTracer sets up the instruction tracer as the flags specify. It
+// returns a function that flushes and closes the trace file.
+func setupTracer(m *arch8.Machine, lines *e8.LineTable) (func(), error) {
+	if *traceFile != "" && *btraceFile != "" {
+		return nil, errors.New("can only write one trace format")
+	}
+
+	path := *traceFile
+	if path == "" {
+		path = *btraceFile
+	}
+	if path == "" {
+		return func() {}, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if *btraceFile != "" {
+		w := arch8.NewTraceWriter(f)
+		m.SetTracer(w)
+		return func() {
+			if err := w.Flush(); err != nil {
+				log.Print(err)
+			}
+			f.Close()
+		}, nil
+	}
+
+	w := bufio.NewWriter(f)
+	t := dasm8.NewTraceText(w)
+	t.SetLines(lines)
+	m.SetTracer(t)
+	return func() {
+		if err := w.Flush(); err != nil {
+			log.Print(err)
+		}
+		f.Close()
+	}, nil
+}
@@ -7,33 +7,78 @@ import (
 	"e8vm.io/e8vm/e8"
 )
 
-// DumpImage disassembles an image.
+// symbolize labels the jump targets with the symbol names.
+func symbolize(lines []*Line, names map[uint32]string) {
+	for _, line := range lines {
+		if line.IsJump {
+			line.ToSym = names[line.To]
+		}
+	}
+}
+
+func dumpLines(
+	out io.Writer, lines []*Line, names map[uint32]string,
+	src *e8.LineTable,
+) {
+	symbolize(lines, names)
+	var pos *e8.LineEntry
+	for _, line := range lines {
+		if name, found := names[line.Addr]; found {
+			fmt.Fprintf(out, "%s:\n", name)
+		}
+		if p := src.Lookup(line.Addr); p != pos {
+			if p != nil {
+				fmt.Fprintf(out, "// %s\n", p)
+			}
+			pos = p
+		}
+		fmt.Fprintln(out, line)
+	}
+}
+
+// DumpImage disassembles an image. If the image has a Symbols section,
+// the functions and variables are labeled with their names. If the image
+// has a DebugInfo section, the code is annotated with the source
+// positions.
 func DumpImage(r io.ReadSeeker, out io.Writer) error {
 	secs, err := e8.Read(r)
 	if err != nil {
 		return err
 	}
 
+	syms, err := e8.FindSymbols(secs)
+	if err != nil {
+		return err
+	}
+	names := make(map[uint32]string)
+	for _, s := range syms {
+		if _, found := names[s.Addr]; !found {
+			names[s.Addr] = s.Name
+		}
+	}
+	src, err := e8.ImageLines(secs)
+	if err != nil {
+		return err
+	}
+
 	for _, sec := range secs {
 		switch sec.Type {
 		case e8.Code:
 			fmt.Fprintln(out, "[code section]")
-			lines := Dasm(sec.Bytes, sec.Addr)
-			for _, line := range lines {
-				fmt.Fprintln(out, line)
-			}
+			dumpLines(out, Dasm(sec.Bytes, sec.Addr), names, src)
 		case e8.Data:
 			fmt.Fprintf(out, "[data of %d bytes at %08x]\n",
 				sec.Size, sec.Addr,
 			)
-			lines := Dasm(sec.Bytes, sec.Addr)
-			for _, line := range lines {
-				fmt.Fprintln(out, line)
-			}
+			dumpLines(out, Dasm(sec.Bytes, sec.Addr), names, nil)
 		case e8.Zeros:
 			fmt.Fprintf(out, "[zeros of %d bytes at %08x]\n",
 				sec.Size, sec.Addr,
 			)
+		case e8.Symbols:
+			fmt.Fprintf(out, "[%d symbols]\n", len(syms))
+		case e8.DebugInfo:
+			fmt.Fprintln(out, "[debug info]")
 		}
 	}
 
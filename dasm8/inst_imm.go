@@ -18,6 +18,8 @@ var (
 		arch8.LBU: "lbu",
 		arch8.SW:  "sw",
 		arch8.SB:  "sb",
+		arch8.LL:  "ll",
+		arch8.SC:  "sc",
 	}
 
 	opImuMap = map[uint32]string{
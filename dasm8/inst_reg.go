@@ -31,6 +31,8 @@ var (
 		arch8.DIVU: "divu",
 		arch8.MOD:  "mod",
 		arch8.MODU: "modu",
+		arch8.CAS:  "cas",
+		arch8.XADD: "xadd",
 	}
 
 	opFloatMap = map[uint32]string{
@@ -39,6 +41,17 @@ var (
 		arch8.FMUL: "fmul",
 		arch8.FDIV: "fdiv",
 		arch8.FINT: "fint",
+		arch8.FEQ:  "feq",
+		arch8.FLT:  "flt",
+		arch8.FLE:  "fle",
+	}
+
+	opFloat2Map = map[uint32]string{
+		arch8.FITOF: "fitof",
+		arch8.FSQRT: "fsqrt",
+		arch8.FABS:  "fabs",
+		arch8.FNEG:  "fneg",
+		arch8.FTOI:  "ftoi",
 	}
 )
 
@@ -68,6 +81,8 @@ func instReg(addr uint32, in uint32) *Line {
 	} else {
 		if opStr, found := opFloatMap[funct]; found {
 			s = fmt.Sprintf("%s %s %s %s", opStr, dest, src1, src2)
+		} else if opStr, found := opFloat2Map[funct]; found {
+			s = fmt.Sprintf("%s %s %s", opStr, dest, src1)
 		}
 	}
 
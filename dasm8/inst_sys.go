@@ -8,16 +8,21 @@ import (
 
 var (
 	opSysMap = map[uint32]string{
-		arch8.HALT:    "halt",
-		arch8.SYSCALL: "syscall",
-		arch8.IRET:    "iret",
-		arch8.PANIC:   "panic",
+		arch8.HALT:     "halt",
+		arch8.SYSCALL:  "syscall",
+		arch8.IRET:     "iret",
+		arch8.PANIC:    "panic",
+		arch8.FENCE:    "fence",
+		arch8.WFI:      "wfi",
+		arch8.TLBFLUSH: "tlbflush",
 	}
 
 	opSys1Map = map[uint32]string{
 		arch8.JRUSER: "jruser",
 		arch8.VTABLE: "vtable",
 		arch8.CPUID:  "cpuid",
+		arch8.IPI:    "ipi",
+		arch8.TLBINV: "tlbinv",
 	}
 )
 
@@ -16,6 +16,7 @@ type Line struct {
 	IsJump bool
 	To     uint32
 	ToLine *Line
+	ToSym  string // name of the jump target, if known
 }
 
 func printables(bs []byte) string {
@@ -43,6 +44,9 @@ func (line *Line) String() string {
 	fmt.Fprintf(ret, "    %s", line.Str)
 	if line.IsJump {
 		fmt.Fprintf(ret, "   // %08x", line.To)
+		if line.ToSym != "" {
+			fmt.Fprintf(ret, " <%s>", line.ToSym)
+		}
 	}
 
 	return ret.String()
@@ -0,0 +1,62 @@
+package dasm8
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/e8"
+)
+
+var regNames = []string{"r0", "r1", "r2", "r3", "r4", "sp", "ret", "pc"}
+
+// TraceLine formats a trace entry into a human readable line.
+func TraceLine(e *arch8.TraceEntry) string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "[%d] %08x: %-20s", e.Core, e.PC, LineStr(e.Inst))
+	for _, r := range e.Regs {
+		fmt.Fprintf(buf, " %s=%08x", regNames[r.Reg], r.Value)
+	}
+	for _, m := range e.Mem {
+		op := "r"
+		if m.Write {
+			op = "w"
+		}
+		fmt.Fprintf(buf, " %s%d@%08x", op, m.Size, m.VirtAddr)
+		if m.PhyAddr != m.VirtAddr {
+			fmt.Fprintf(buf, "(%08x)", m.PhyAddr)
+		}
+	}
+	if e.Excep != nil {
+		fmt.Fprintf(buf, " !%s", e.Excep)
+	}
+	return buf.String()
+}
+
+// TraceText is a tracer that writes the trace in human readable lines.
+type TraceText struct {
+	w     io.Writer
+	lines *e8.LineTable
+}
+
+// NewTraceText creates a tracer that writes the trace into w.
+func NewTraceText(w io.Writer) *TraceText {
+	return &TraceText{w: w}
+}
+
+// SetLines sets the line table that annotates the trace lines with the
+// source positions.
+func (t *TraceText) SetLines(lines *e8.LineTable) { t.lines = lines }
+
+// Trace writes a trace entry as a line.
+func (t *TraceText) Trace(e *arch8.TraceEntry) {
+	line := TraceLine(e)
+	if pos := t.lines.Lookup(e.PC); pos != nil {
+		line += " // " + pos.String()
+	}
+	if _, err := fmt.Fprintln(t.w, line); err != nil {
+		log.Print(err)
+	}
+}
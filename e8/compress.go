@@ -0,0 +1,58 @@
+package e8
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+)
+
+// maxInflated limits the total size of the compressed sections of an
+// image after inflating, so that a small malicious image cannot take up
+// all the memory.
+const maxInflated = 256 << 20
+
+func deflate(bs []byte) []byte {
+	buf := new(bytes.Buffer)
+	w, err := flate.NewWriter(buf, flate.BestCompression)
+	if err != nil {
+		panic(err) // only on invalid level
+	}
+	w.Write(bs) // writes into a buffer never fail
+	w.Close()
+	return buf.Bytes()
+}
+
+// inflater inflates the compressed sections of an image, and limits the
+// total number of bytes inflated.
+type inflater struct {
+	left int64
+}
+
+func newInflater() *inflater { return &inflater{left: maxInflated} }
+
+// inflate reads a compressed section of n bytes from r. The buffer grows
+// as the bytes are inflated, rather than being allocated by the size
+// in the header.
+func (in *inflater) inflate(r io.Reader, n uint32) ([]byte, error) {
+	if int64(n) > in.left {
+		return nil, errors.New("compressed sections too large")
+	}
+	in.left -= int64(n)
+
+	fr := flate.NewReader(r)
+	defer fr.Close()
+
+	// read one more byte to find out a section larger than its size
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, io.LimitReader(fr, int64(n)+1)); err != nil {
+		return nil, err
+	}
+	if int64(buf.Len()) < int64(n) {
+		return nil, errors.New("compressed section smaller than its size")
+	}
+	if int64(buf.Len()) > int64(n) {
+		return nil, errors.New("compressed section larger than its size")
+	}
+	return buf.Bytes(), nil
+}
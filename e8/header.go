@@ -5,6 +5,13 @@ import (
 	"io"
 )
 
+// Section flags
+const (
+	FlagReadonly   uint8 = 1 << iota // not writable when loaded
+	FlagExec                         // executable when loaded
+	FlagCompressed                   // bytes compressed with DEFLATE
+)
+
 // Header is a section header in the executable file.
 type Header struct {
 	Type uint8
@@ -14,9 +21,14 @@ type Header struct {
 	Size uint32
 
 	offset uint32
+	crc    uint32 // checksum of the bytes, since version 1
 }
 
-const sectionLen = 16
+// section header lengths of version 0 and since version 1
+const (
+	sectionLenV0 = 16
+	sectionLen   = 20
+)
 
 // ReadHeader reads a new section header from the reader.
 func ReadHeader(r io.Reader) (*Header, error) {
@@ -37,6 +49,7 @@ func (h *Header) WriteTo(w io.Writer) (int64, error) {
 	enc.PutUint32(buf[4:8], h.Addr)
 	enc.PutUint32(buf[8:12], h.Size)
 	enc.PutUint32(buf[12:16], h.offset)
+	enc.PutUint32(buf[16:20], h.crc)
 
 	n, err := w.Write(buf)
 	return int64(n), err
@@ -44,10 +57,16 @@ func (h *Header) WriteTo(w io.Writer) (int64, error) {
 
 // ReadFrom read in the section from a reader.
 func (h *Header) ReadFrom(r io.Reader) (int64, error) {
-	buf := make([]byte, sectionLen)
-	n, err := io.ReadFull(r, buf)
+	return h.readFrom(r, sectionLen)
+}
+
+// readFrom reads in a section header of n bytes, where n is the header
+// length of the image version.
+func (h *Header) readFrom(r io.Reader, n int) (int64, error) {
+	buf := make([]byte, n)
+	nread, err := io.ReadFull(r, buf)
 	if err != nil {
-		return int64(n), err
+		return int64(nread), err
 	}
 
 	enc := binary.LittleEndian
@@ -56,5 +75,8 @@ func (h *Header) ReadFrom(r io.Reader) (int64, error) {
 	h.Addr = enc.Uint32(buf[4:8])
 	h.Size = enc.Uint32(buf[8:12])
 	h.offset = enc.Uint32(buf[12:16])
-	return int64(n), nil
+	if n >= sectionLen {
+		h.crc = enc.Uint32(buf[16:20])
+	}
+	return int64(nread), nil
 }
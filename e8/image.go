@@ -0,0 +1,264 @@
+package e8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// Magic is the first 4 bytes of an image since version 1. Images of
+// version 0 start with the first section header instead.
+var Magic = [4]byte{0x7f, 'E', '8', 'I'}
+
+// Version is the version of the image format that Write writes.
+const Version = 1
+
+// Image flags
+const (
+	ImageHasEntry uint16 = 1 << iota // the entry address is set
+)
+
+// imageHeaderLen is the length of the image header. The image header is
+// the magic, the version, the flags, the entry address and the checksum
+// of the section headers, all little endian.
+const imageHeaderLen = 16
+
+// Image is an executable file.
+type Image struct {
+	Version  uint16 // version read in; Write always writes Version
+	Entry    uint32
+	HasEntry bool
+	Sections []*Section
+}
+
+var enc = binary.LittleEndian
+
+// readHeaders reads the section headers until the empty terminating one,
+// each of n bytes. The bytes read are also written into raw.
+func readHeaders(r io.Reader, n int, raw io.Writer) ([]*Section, error) {
+	r = io.TeeReader(r, raw)
+	var ret []*Section
+	for {
+		h := new(Header)
+		if _, err := h.readFrom(r, n); err != nil {
+			return nil, err
+		}
+		if h.Type == None {
+			break
+		}
+		ret = append(ret, &Section{Header: h})
+	}
+	return ret, nil
+}
+
+// readImageHeader reads the image header. It returns nil if the image
+// has no magic, which is an image of version 0.
+func readImageHeader(r io.ReadSeeker) (*Image, uint32, error) {
+	var buf [imageHeaderLen]byte
+	n, err := io.ReadFull(r, buf[:])
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, 0, err
+	}
+	if n < len(Magic) || !bytes.Equal(buf[:4], Magic[:]) {
+		if _, err := r.Seek(0, 0); err != nil {
+			return nil, 0, err
+		}
+		return nil, 0, nil
+	}
+	if n < imageHeaderLen {
+		return nil, 0, errors.New("image header truncated")
+	}
+
+	img := &Image{Version: enc.Uint16(buf[4:6])}
+	if img.Version == 0 || img.Version > Version {
+		return nil, 0, fmt.Errorf("unsupported image version %d",
+			img.Version,
+		)
+	}
+	img.HasEntry = enc.Uint16(buf[6:8])&ImageHasEntry != 0
+	img.Entry = enc.Uint32(buf[8:12])
+	return img, enc.Uint32(buf[12:16]), nil
+}
+
+// ReadImage reads in an executable file, and checks the checksums and
+// the bounds of the sections. Compressed sections are inflated. Images
+// of version 0, which have no image header or checksums, are still read
+// in; their code sections are flagged as executable.
+func ReadImage(r io.ReadSeeker) (*Image, error) {
+	fileSize, err := r.Seek(0, 2)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	img, headerCRC, err := readImageHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	n := sectionLen
+	if img == nil {
+		img = new(Image)
+		n = sectionLenV0
+	}
+
+	raw := crc32.NewIEEE()
+	img.Sections, err = readHeaders(r, n, raw)
+	if err != nil {
+		return nil, err
+	}
+	if img.Version > 0 && raw.Sum32() != headerCRC {
+		return nil, errors.New("section headers checksum mismatch")
+	}
+
+	inf := newInflater()
+	for _, s := range img.Sections {
+		if img.Version == 0 && s.Type == Code {
+			s.Flag |= FlagExec
+		}
+		if s.Type == Zeros {
+			continue
+		}
+
+		if err := readSection(r, s, fileSize, inf); err != nil {
+			return nil, err
+		}
+		if img.Version > 0 && crc32.ChecksumIEEE(s.Bytes) != s.crc {
+			return nil, fmt.Errorf(
+				"section at %08x checksum mismatch", s.Addr,
+			)
+		}
+	}
+
+	if img.HasEntry && !img.inExec(img.Entry) {
+		return nil, fmt.Errorf("entry %08x not in executable section",
+			img.Entry,
+		)
+	}
+	return img, nil
+}
+
+// readSection reads in the bytes of a section, and inflates them with inf
+// if the section is compressed.
+func readSection(
+	r io.ReadSeeker, s *Section, fileSize int64, inf *inflater,
+) error {
+	compressed := s.Flag&FlagCompressed != 0
+	end := int64(s.offset)
+	if !compressed {
+		end += int64(s.Size)
+	}
+	if end > fileSize {
+		return fmt.Errorf("section at %08x out of file", s.Addr)
+	}
+	if _, err := r.Seek(int64(s.offset), 0); err != nil {
+		return err
+	}
+
+	if compressed {
+		bs, err := inf.inflate(r, s.Size)
+		if err != nil {
+			return fmt.Errorf("section at %08x: %s", s.Addr, err)
+		}
+		s.Bytes = bs
+		return nil
+	}
+
+	s.Bytes = make([]byte, s.Size)
+	_, err := io.ReadFull(r, s.Bytes)
+	return err
+}
+
+// inExec checks if an address is in an executable section.
+func (img *Image) inExec(addr uint32) bool {
+	for _, s := range img.Sections {
+		if s.Flag&FlagExec == 0 {
+			continue
+		}
+		if addr >= s.Addr && addr-s.Addr < s.Size {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteImage writes an image into a writer, in the current version.
+func WriteImage(w io.Writer, img *Image) error {
+	nsec := int64(len(img.Sections) + 1)
+	offset := imageHeaderLen + sectionLen*nsec
+	if offset > math.MaxUint32 {
+		return errors.New("too many headers")
+	}
+
+	headers := new(bytes.Buffer)
+	payloads := make([][]byte, len(img.Sections))
+	inflated := int64(0) // total size of the compressed sections
+	for i, s := range img.Sections {
+		if int64(len(s.Bytes)) > math.MaxUint32 {
+			return errors.New("too many bytes in a section")
+		}
+
+		payload := s.Bytes
+		if s.Bytes != nil && s.Flag&FlagCompressed != 0 {
+			inflated += int64(len(s.Bytes))
+			if inflated > maxInflated {
+				return errors.New("sections too large to compress")
+			}
+			payload = deflate(s.Bytes)
+		}
+		payloads[i] = payload
+		if offset > math.MaxUint32-int64(len(payload)) {
+			return errors.New("too many bytes in total")
+		}
+
+		var h = *s.Header
+
+		if s.Bytes != nil {
+			h.Size = uint32(len(s.Bytes))
+			h.offset = uint32(offset)
+			h.crc = crc32.ChecksumIEEE(s.Bytes)
+		} else {
+			h.offset = 0
+			h.crc = 0
+		}
+
+		h.WriteTo(headers)
+		offset += int64(len(payload))
+	}
+
+	// write an empty header for terminating the headers.
+	var empty Header
+	empty.WriteTo(headers)
+
+	var buf [imageHeaderLen]byte
+	copy(buf[:4], Magic[:])
+	enc.PutUint16(buf[4:6], Version)
+	if img.HasEntry {
+		enc.PutUint16(buf[6:8], ImageHasEntry)
+		enc.PutUint32(buf[8:12], img.Entry)
+	}
+	enc.PutUint32(buf[12:16], crc32.ChecksumIEEE(headers.Bytes()))
+	if _, err := w.Write(buf[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(headers.Bytes()); err != nil {
+		return err
+	}
+
+	// now the contents.
+	for _, payload := range payloads {
+		if payload == nil {
+			continue
+		}
+		if _, err := w.Write(payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package e8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestImage(t *testing.T) {
+	img := &Image{
+		Entry:    0x8004,
+		HasEntry: true,
+		Sections: []*Section{
+			{
+				Header: &Header{
+					Type: Code,
+					Flag: FlagReadonly | FlagExec,
+					Addr: 0x8000,
+				},
+				Bytes: []byte{1, 2, 3, 4, 5, 6, 7, 8},
+			},
+			{
+				Header: &Header{Type: Zeros, Addr: 0x9000, Size: 64},
+			},
+		},
+	}
+	buf := new(bytes.Buffer)
+	if err := WriteImage(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	bs := buf.Bytes()
+
+	got, err := ReadImage(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != Version || !got.HasEntry || got.Entry != 0x8004 {
+		t.Errorf("got image %+v", got)
+	}
+	if len(got.Sections) != 2 {
+		t.Fatalf("got %d sections", len(got.Sections))
+	}
+	code := got.Sections[0]
+	if code.Flag != FlagReadonly|FlagExec || code.Size != 8 ||
+		!bytes.Equal(code.Bytes, img.Sections[0].Bytes) {
+		t.Errorf("got code section %+v", code)
+	}
+	if zeros := got.Sections[1]; zeros.Size != 64 || zeros.Bytes != nil {
+		t.Errorf("got zeros section %+v", zeros)
+	}
+
+	bad := func(name string, f func(bs []byte)) {
+		cp := make([]byte, len(bs))
+		copy(cp, bs)
+		f(cp)
+		if _, err := ReadImage(bytes.NewReader(cp)); err == nil {
+			t.Errorf("%s: read without error", name)
+		}
+	}
+	bad("corrupted bytes", func(bs []byte) { bs[len(bs)-1]++ })
+	bad("corrupted header", func(bs []byte) { bs[imageHeaderLen+4]++ })
+	bad("new version", func(bs []byte) { bs[4] = Version + 1 })
+	bad("entry outside", func(bs []byte) { bs[9] = 0x90 })
+	if _, err := ReadImage(bytes.NewReader(bs[:len(bs)-1])); err == nil {
+		t.Error("truncated image read without error")
+	}
+}
+
+func TestImageV0(t *testing.T) {
+	// a version 0 image has only the 16-byte section headers
+	enc := binary.LittleEndian
+	bs := make([]byte, 36)
+	bs[0] = Code
+	enc.PutUint32(bs[4:8], 0x8000)
+	enc.PutUint32(bs[8:12], 4)
+	enc.PutUint32(bs[12:16], 32)
+	copy(bs[32:], []byte{1, 2, 3, 4})
+
+	img, err := ReadImage(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Version != 0 || img.HasEntry || len(img.Sections) != 1 {
+		t.Fatalf("got image %+v", img)
+	}
+	code := img.Sections[0]
+	if code.Addr != 0x8000 || code.Flag != FlagExec ||
+		!bytes.Equal(code.Bytes, []byte{1, 2, 3, 4}) {
+		t.Errorf("got code section %+v", code)
+	}
+}
+
+func TestImageCompressed(t *testing.T) {
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i % 7)
+	}
+	img := &Image{Sections: []*Section{{
+		Header: &Header{Type: Data, Flag: FlagCompressed, Addr: 0x9000},
+		Bytes:  data,
+	}}}
+	buf := new(bytes.Buffer)
+	if err := WriteImage(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() >= len(data) {
+		t.Errorf("image of %d bytes not compressed", buf.Len())
+	}
+
+	got, err := ReadImage(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := got.Sections[0]
+	if s.Size != uint32(len(data)) || !bytes.Equal(s.Bytes, data) ||
+		s.Flag != FlagCompressed {
+		t.Errorf("got section of %d bytes, flag %d", s.Size, s.Flag)
+	}
+
+	compressed := deflate(data)
+	for _, n := range []uint32{
+		uint32(len(data)) - 1, uint32(len(data)) + 1,
+		maxInflated, maxInflated + 1,
+	} {
+		r := bytes.NewReader(compressed)
+		if _, err := newInflater().inflate(r, n); err == nil {
+			t.Errorf("inflated %d bytes without error", n)
+		}
+	}
+
+	// the limit is on the total size of all the sections
+	inf := &inflater{left: int64(len(data)) * 3 / 2}
+	n := uint32(len(data))
+	if _, err := inf.inflate(bytes.NewReader(compressed), n); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inf.inflate(bytes.NewReader(compressed), n); err == nil {
+		t.Error("inflated over the total limit without error")
+	}
+}
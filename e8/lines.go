@@ -0,0 +1,166 @@
+package e8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// LineEntry maps the code starting at an address to a source position.
+// An entry with an empty file ends the code of the previous entry.
+type LineEntry struct {
+	Addr uint32
+	File string
+	Line uint32
+	Col  uint32
+}
+
+func (e *LineEntry) String() string {
+	return fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Col)
+}
+
+type lineHeader struct {
+	Addr uint32
+	File uint32 // index in the file table
+	Line uint32
+	Col  uint32
+}
+
+// EncodeLines encodes the line entries into the bytes of a DebugInfo
+// section. It starts with the file table, which is the number of files,
+// and the length and the name of each file. The entries follow, each is
+// its address, file index, line and column, all little endian.
+func EncodeLines(ents []*LineEntry) []byte {
+	files := make(map[string]uint32)
+	var names []string
+	for _, e := range ents {
+		if _, found := files[e.File]; !found {
+			files[e.File] = uint32(len(names))
+			names = append(names, e.File)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, uint32(len(names)))
+	for _, name := range names {
+		binary.Write(buf, binary.LittleEndian, uint32(len(name)))
+		buf.WriteString(name)
+	}
+	for _, e := range ents {
+		h := &lineHeader{e.Addr, files[e.File], e.Line, e.Col}
+		binary.Write(buf, binary.LittleEndian, h)
+	}
+	return buf.Bytes()
+}
+
+// DecodeLines decodes the bytes of a DebugInfo section.
+func DecodeLines(bs []byte) ([]*LineEntry, error) {
+	r := bytes.NewReader(bs)
+	var nfile uint32
+	if err := binary.Read(r, binary.LittleEndian, &nfile); err != nil {
+		return nil, err
+	}
+	if int64(nfile)*4 > int64(r.Len()) {
+		return nil, errors.New("file table too large")
+	}
+	files := make([]string, nfile)
+	for i := range files {
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, err
+		}
+		if n > maxSymbolName {
+			return nil, errors.New("file name too long")
+		}
+		name := make([]byte, n)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, err
+		}
+		files[i] = string(name)
+	}
+
+	var ret []*LineEntry
+	for r.Len() > 0 {
+		var h lineHeader
+		if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+			return nil, err
+		}
+		if h.File >= nfile {
+			return nil, errors.New("invalid file index")
+		}
+		ret = append(ret, &LineEntry{
+			Addr: h.Addr,
+			File: files[h.File],
+			Line: h.Line,
+			Col:  h.Col,
+		})
+	}
+	return ret, nil
+}
+
+// FindLines returns the line entries in the DebugInfo sections, or nil if
+// there is none.
+func FindLines(secs []*Section) ([]*LineEntry, error) {
+	var ret []*LineEntry
+	for _, s := range secs {
+		if s.Type != DebugInfo {
+			continue
+		}
+		ents, err := DecodeLines(s.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, ents...)
+	}
+	return ret, nil
+}
+
+// LineTable maps code addresses to source positions.
+type LineTable struct {
+	ents []*LineEntry
+}
+
+type byLineAddr []*LineEntry
+
+func (l byLineAddr) Len() int           { return len(l) }
+func (l byLineAddr) Less(i, j int) bool { return l[i].Addr < l[j].Addr }
+func (l byLineAddr) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// NewLineTable creates a line table from a list of line entries.
+func NewLineTable(ents []*LineEntry) *LineTable {
+	ret := &LineTable{ents: make([]*LineEntry, len(ents))}
+	copy(ret.ents, ents)
+	sort.Stable(byLineAddr(ret.ents))
+	return ret
+}
+
+// ImageLines reads the line table from the DebugInfo sections of an
+// image, or returns nil if the image has no line entries.
+func ImageLines(secs []*Section) (*LineTable, error) {
+	ents, err := FindLines(secs)
+	if err != nil || ents == nil {
+		return nil, err
+	}
+	return NewLineTable(ents), nil
+}
+
+// Lookup returns the line entry that covers the address, or nil if the
+// address has no source position. A nil table has no entries.
+func (t *LineTable) Lookup(addr uint32) *LineEntry {
+	if t == nil {
+		return nil
+	}
+	n := len(t.ents)
+	i := sort.Search(n, func(i int) bool { return t.ents[i].Addr > addr })
+	if i == 0 {
+		return nil
+	}
+	ret := t.ents[i-1]
+	if ret.File == "" {
+		return nil
+	}
+	return ret
+}
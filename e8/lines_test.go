@@ -0,0 +1,66 @@
+package e8
+
+import (
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	ents := []*LineEntry{
+		{Addr: 0x8000, File: "a.g", Line: 1, Col: 6},
+		{Addr: 0x8010, File: "b.g", Line: 3, Col: 2},
+		{Addr: 0x8018, File: "a.g", Line: 2, Col: 2},
+		{Addr: 0x8020},
+	}
+	secs := []*Section{
+		{Header: &Header{Type: DebugInfo}, Bytes: EncodeLines(ents)},
+	}
+	if secs[0].Loadable() {
+		t.Error("debug info is loadable")
+	}
+
+	got, err := FindLines(secs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(ents) {
+		t.Fatalf("got %d entries, want %d", len(got), len(ents))
+	}
+	for i, e := range got {
+		if *e != *ents[i] {
+			t.Errorf("entry %d: got %+v, want %+v", i, e, ents[i])
+		}
+	}
+
+	tab, err := ImageLines(secs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		addr uint32
+		pos  string
+	}{
+		{0x7ffc, ""},
+		{0x8000, "a.g:1:6"},
+		{0x800c, "a.g:1:6"},
+		{0x8014, "b.g:3:2"},
+		{0x801c, "a.g:2:2"},
+		{0x8020, ""},
+		{0x9000, ""},
+	} {
+		pos := ""
+		if e := tab.Lookup(test.addr); e != nil {
+			pos = e.String()
+		}
+		if pos != test.pos {
+			t.Errorf("lookup %08x: got %q, want %q", test.addr, pos, test.pos)
+		}
+	}
+
+	bs := secs[0].Bytes
+	if _, err := DecodeLines(bs[:len(bs)-1]); err == nil {
+		t.Error("truncated lines decoded")
+	}
+	if tab, err := ImageLines(nil); err != nil || tab != nil {
+		t.Errorf("got %v, %v without a debug info section", tab, err)
+	}
+}
@@ -0,0 +1,85 @@
+package e8
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// PageMap is a virtual page to map when loading an image.
+type PageMap struct {
+	VPN      uint32 // virtual page number
+	Readonly bool
+	Exec     bool
+	User     bool   // accessible in user mode
+	Bytes    []byte // content of the page, a whole page
+}
+
+type byVPN []*PageMap
+
+func (l byVPN) Len() int           { return len(l) }
+func (l byVPN) Less(i, j int) bool { return l[i].VPN < l[j].VPN }
+func (l byVPN) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// MapPages lays out the loadable sections of an image into virtual pages
+// for a program that runs in the ring. The pages are readonly and
+// executable as the section flags say, and are accessible in user mode
+// when the ring is not 0. A page shared by several sections is readonly
+// only when all of them are readonly, and executable when any of them is
+// executable. The pages are ordered by the page numbers.
+func MapPages(secs []*Section, pageSize uint32, ring byte) (
+	[]*PageMap, error,
+) {
+	if pageSize == 0 || pageSize&(pageSize-1) != 0 {
+		return nil, errors.New("page size not a power of 2")
+	}
+
+	pages := make(map[uint32]*PageMap)
+	for _, s := range secs {
+		if !s.Loadable() || s.Size == 0 {
+			continue
+		}
+		if s.Addr+(s.Size-1) < s.Addr {
+			return nil, fmt.Errorf("section at %08x overflows", s.Addr)
+		}
+
+		first := s.Addr / pageSize
+		last := (s.Addr + s.Size - 1) / pageSize
+		for vpn := first; ; vpn++ {
+			p := pages[vpn]
+			if p == nil {
+				p = &PageMap{
+					VPN:      vpn,
+					Readonly: true,
+					User:     ring > 0,
+					Bytes:    make([]byte, pageSize),
+				}
+				pages[vpn] = p
+			}
+			if s.Flag&FlagReadonly == 0 {
+				p.Readonly = false
+			}
+			if s.Flag&FlagExec != 0 {
+				p.Exec = true
+			}
+			if vpn == last {
+				break
+			}
+		}
+
+		if s.Type == Zeros {
+			continue
+		}
+		for i, b := range s.Bytes {
+			addr := s.Addr + uint32(i)
+			pages[addr/pageSize].Bytes[addr%pageSize] = b
+		}
+	}
+
+	ret := make([]*PageMap, 0, len(pages))
+	for _, p := range pages {
+		ret = append(ret, p)
+	}
+	sort.Sort(byVPN(ret))
+	return ret, nil
+}
@@ -0,0 +1,58 @@
+package e8
+
+import (
+	"testing"
+)
+
+func TestMapPages(t *testing.T) {
+	secs := []*Section{{
+		Header: &Header{
+			Type: Code,
+			Flag: FlagReadonly | FlagExec,
+			Addr: 0xff8,
+			Size: 12,
+		},
+		Bytes: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+	}, {
+		Header: &Header{Type: Data, Addr: 0x1800, Size: 1},
+		Bytes:  []byte{13},
+	}, {
+		Header: &Header{Type: Zeros, Addr: 0x3000, Size: 0x10},
+	}, {
+		Header: &Header{Type: Symbols, Size: 4},
+		Bytes:  []byte{1, 2, 3, 4},
+	}}
+
+	if _, err := MapPages(secs, 1000, 0); err == nil {
+		t.Error("mapped with an invalid page size")
+	}
+	pages, err := MapPages(secs, 0x1000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		vpn            uint32
+		readonly, exec bool
+	}{
+		{0, true, true},
+		{1, false, true}, // shared by code and data
+		{3, false, false},
+	}
+	if len(pages) != len(want) {
+		t.Fatalf("got %d pages, want %d", len(pages), len(want))
+	}
+	for i, w := range want {
+		p := pages[i]
+		if p.VPN != w.vpn || p.Readonly != w.readonly ||
+			p.Exec != w.exec || !p.User || len(p.Bytes) != 0x1000 {
+			t.Errorf("page %d: got %d, readonly=%t, exec=%t",
+				i, p.VPN, p.Readonly, p.Exec,
+			)
+		}
+	}
+	if pages[0].Bytes[0xff8] != 1 || pages[1].Bytes[3] != 12 ||
+		pages[1].Bytes[0x800] != 13 {
+		t.Error("wrong page contents")
+	}
+}
@@ -1,9 +1,7 @@
 package e8
 
 import (
-	"errors"
 	"io"
-	"math"
 	"os"
 )
 
@@ -13,41 +11,14 @@ type Section struct {
 	Bytes []byte
 }
 
-func readHeaders(r io.Reader) ([]*Section, error) {
-	var ret []*Section
-	for {
-		h, err := ReadHeader(r)
-		if err != nil {
-			return nil, err
-		}
-		if h.Type == None {
-			break
-		}
-		ret = append(ret, &Section{Header: h})
-	}
-	return ret, nil
-}
-
-// Read reads in an executable file
+// Read reads in an executable file. It returns only the sections; use
+// ReadImage for the entry address.
 func Read(r io.ReadSeeker) ([]*Section, error) {
-	ret, err := readHeaders(r)
+	img, err := ReadImage(r)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, s := range ret {
-		if s.Type == Zeros {
-			continue
-		}
-
-		s.Bytes = make([]byte, s.Size)
-		r.Seek(int64(s.offset), 0)
-		if _, err := io.ReadFull(r, s.Bytes); err != nil {
-			return nil, err
-		}
-	}
-
-	return ret, nil
+	return img.Sections, nil
 }
 
 // Open opens an executable file from the file system.
@@ -60,54 +31,10 @@ func Open(path string) ([]*Section, error) {
 	return Read(f)
 }
 
-// Write writes the sections into a writer.
+// Write writes the sections into a writer, as an image with no entry
+// address.
 func Write(w io.Writer, sections []*Section) error {
-	offset := int64(sectionLen * (len(sections) + 1))
-	if offset > math.MaxUint32 {
-		return errors.New("too many headers")
-	}
-
-	for _, s := range sections {
-		if int64(len(s.Bytes)) > math.MaxUint32 {
-			return errors.New("too many bytes in a section")
-		}
-		if offset > math.MaxUint32-int64(len(s.Bytes)) {
-			return errors.New("too many bytes in total")
-		}
-
-		var h = *s.Header
-
-		if s.Bytes != nil {
-			h.Size = uint32(len(s.Bytes))
-			h.offset = uint32(offset)
-		} else {
-			h.offset = 0
-		}
-
-		if _, err := h.WriteTo(w); err != nil {
-			return err
-		}
-
-		offset += int64(len(s.Bytes))
-	}
-
-	// write an empty header for terminating the headers.
-	var empty Header
-	if _, err := empty.WriteTo(w); err != nil {
-		return err
-	}
-
-	// now the contents.
-	for _, s := range sections {
-		if s.Bytes == nil {
-			continue
-		}
-		if _, err := w.Write(s.Bytes); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return WriteImage(w, &Image{Sections: sections})
 }
 
 // Create creates an executable file.
@@ -0,0 +1,98 @@
+package e8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Symbol types
+const (
+	SymFunc uint8 = 1
+	SymVar  uint8 = 2
+)
+
+// Symbol is a named function or variable in an image.
+type Symbol struct {
+	Type uint8
+	Name string
+	Addr uint32
+	Size uint32
+}
+
+type symbolHeader struct {
+	Type uint8
+	Addr uint32
+	Size uint32
+	N    uint32 // length of the name
+}
+
+// maxSymbolName limits the length of a symbol name.
+const maxSymbolName = 1 << 16
+
+// EncodeSymbols encodes the symbols into the bytes of a Symbols section.
+// Each symbol is its type, address, size, length of the name and the
+// name, all little endian.
+func EncodeSymbols(syms []*Symbol) []byte {
+	buf := new(bytes.Buffer)
+	for _, s := range syms {
+		h := &symbolHeader{s.Type, s.Addr, s.Size, uint32(len(s.Name))}
+		binary.Write(buf, binary.LittleEndian, h)
+		buf.WriteString(s.Name)
+	}
+	return buf.Bytes()
+}
+
+// DecodeSymbols decodes the bytes of a Symbols section.
+func DecodeSymbols(bs []byte) ([]*Symbol, error) {
+	r := bytes.NewReader(bs)
+	var ret []*Symbol
+	for r.Len() > 0 {
+		var h symbolHeader
+		if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+			return nil, err
+		}
+		if h.N > maxSymbolName {
+			return nil, errors.New("symbol name too long")
+		}
+		name := make([]byte, h.N)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, err
+		}
+		ret = append(ret, &Symbol{
+			Type: h.Type,
+			Name: string(name),
+			Addr: h.Addr,
+			Size: h.Size,
+		})
+	}
+	return ret, nil
+}
+
+// FindSymbols returns the symbols in the Symbols sections, or nil if
+// there is none.
+func FindSymbols(secs []*Section) ([]*Symbol, error) {
+	var ret []*Symbol
+	for _, s := range secs {
+		if s.Type != Symbols {
+			continue
+		}
+		syms, err := DecodeSymbols(s.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, syms...)
+	}
+	return ret, nil
+}
+
+// Loadable checks if a section is loaded into the memory. Symbols, debug
+// info and comments are not.
+func (s *Section) Loadable() bool {
+	switch s.Type {
+	case Code, Data, Zeros:
+		return true
+	}
+	return false
+}
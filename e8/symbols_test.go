@@ -0,0 +1,41 @@
+package e8
+
+import (
+	"testing"
+)
+
+func TestSymbols(t *testing.T) {
+	syms := []*Symbol{
+		{SymFunc, "main.main", 0x8000, 24},
+		{SymVar, "main.x", 0x9000, 4},
+		{SymFunc, "", 0x8018, 0},
+	}
+	secs := []*Section{
+		{Header: &Header{Type: Code}, Bytes: []byte{0, 0, 0, 0}},
+		{Header: &Header{Type: Symbols}, Bytes: EncodeSymbols(syms)},
+	}
+	if !secs[0].Loadable() || secs[1].Loadable() {
+		t.Error("wrong loadable sections")
+	}
+
+	got, err := FindSymbols(secs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(syms) {
+		t.Fatalf("got %d symbols, want %d", len(got), len(syms))
+	}
+	for i, s := range got {
+		if *s != *syms[i] {
+			t.Errorf("symbol %d: got %+v, want %+v", i, s, syms[i])
+		}
+	}
+
+	bs := secs[1].Bytes
+	if _, err := DecodeSymbols(bs[:len(bs)-1]); err == nil {
+		t.Error("truncated symbols decoded")
+	}
+	if got, err := FindSymbols(secs[:1]); err != nil || got != nil {
+		t.Errorf("got %v, %v without a symbols section", got, err)
+	}
+}
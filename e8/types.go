@@ -1,6 +1,10 @@
 // Package e8 defines the file format that saves an executable file.
 package e8
 
+import (
+	"fmt"
+)
+
 // Section types
 const (
 	None uint8 = iota
@@ -11,3 +15,21 @@ const (
 	DebugInfo
 	Comment
 )
+
+var typeNames = map[uint8]string{
+	None:      "none",
+	Code:      "code",
+	Data:      "data",
+	Zeros:     "zeros",
+	Symbols:   "symbols",
+	DebugInfo: "debuginfo",
+	Comment:   "comment",
+}
+
+// TypeName returns the name of a section type.
+func TypeName(t uint8) string {
+	if name, found := typeNames[t]; found {
+		return name
+	}
+	return fmt.Sprintf("type%d", t)
+}
@@ -0,0 +1,22 @@
+package e8
+
+import (
+	"testing"
+)
+
+func TestTypeName(t *testing.T) {
+	for _, test := range []struct {
+		t    uint8
+		name string
+	}{
+		{Code, "code"},
+		{Zeros, "zeros"},
+		{DebugInfo, "debuginfo"},
+		{Comment, "comment"},
+		{42, "type42"},
+	} {
+		if got := TypeName(test.t); got != test.name {
+			t.Errorf("type %d: got %q, want %q", test.t, got, test.name)
+		}
+	}
+}
@@ -130,6 +130,10 @@ func opAssign(b *builder, dest, src *ref, op *lex8.Token) {
 		case types.Int, types.Int8, types.Uint, types.Uint8:
 			opAssignInt(b, opOp, dest, src)
 			return
+		case types.Float32:
+			if opAssignFloat(b, opOp, dest, src) {
+				return
+			}
 		}
 	}
 
@@ -0,0 +1,43 @@
+package ast
+
+import (
+	"fmt"
+
+	"e8vm.io/e8vm/lex8"
+)
+
+// StmtPos returns the starting position of a statement.
+func StmtPos(s Stmt) *lex8.Pos {
+	switch s := s.(type) {
+	case *EmptyStmt:
+		return s.Semi.Pos
+	case *ExprStmt:
+		return ExprPos(s.Expr)
+	case *IncStmt:
+		return ExprPos(s.Expr)
+	case *DefineStmt:
+		return ExprPos(s.Left)
+	case *AssignStmt:
+		return ExprPos(s.Left)
+	case *IfStmt:
+		return s.If.Pos
+	case *ForStmt:
+		return s.Kw.Pos
+	case *BlockStmt:
+		return s.Lbrace.Pos
+	case *Block:
+		return s.Lbrace.Pos
+	case *VarDecls:
+		return s.Kw.Pos
+	case *ConstDecls:
+		return s.Kw.Pos
+	case *ReturnStmt:
+		return s.Kw.Pos
+	case *ContinueStmt:
+		return s.Kw.Pos
+	case *BreakStmt:
+		return s.Kw.Pos
+	default:
+		panic(fmt.Errorf("invalid statement type: %T", s))
+	}
+}
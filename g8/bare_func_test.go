@@ -125,6 +125,13 @@ func TestBareFunc_good(t *testing.T) {
 	o("var a [7]int; s:=a[:]; a[3]=33; pt:=&s[3]; printInt(*pt)", "33")
 	o("a:=3; a++; printInt(a)", "4")
 	o("a:=3; pt := &a; *pt++; printInt(a)", "4")
+	o("var a uint; printUint(atomicAdd(&a, 3)); printUint(a)", "0\n3")
+	o("var a uint=5; printUint(atomicCAS(&a, 5, 7)); printUint(a)", "5\n7")
+	o("var a uint=5; atomicCAS(&a, 4, 7); printUint(a)", "5")
+	o("var a uint; b:=loadLinked(&a); storeCond(&a, b+1); printUint(a)",
+		"1")
+	o("var a uint; loadLinked(&a); a=2; fence(); "+
+		"if !storeCond(&a, 1) { printUint(a) }", "2")
 
 	o("printInt(int(byte(int(-1))))", "255")
 	o("printInt(int(byte(3)))", "3")
@@ -164,6 +171,18 @@ func TestBareFunc_good(t *testing.T) {
 	o("const a = 33; printUint(a)", "33")
 	o("const ( a,b=3,4; c=a+b ); printInt(a+b+c)", "14")
 	o("const a,b=3,4; var v [a+b]int; printInt(len(v))", "7")
+
+	o("a:=1.5; b:=2.25; printInt(int((a+b)*4))", "15")
+	o("a:=1.5e1; printInt(int(a/2*-1))", "-7")
+	o("var a float=3; printInt(int(a*a-a))", "6")
+	o("a:=int(-2.5); printInt(a)", "-2")
+	o("a:=7; b:=float(a)/2; printInt(int(b*10))", "35")
+	o("a:=2.; a+=1; a*=a; a-=5e0; a/=2; printInt(int(a))", "2")
+	o("a:=sqrt(6.25); printInt(int(a*10))", "25")
+	o("a, b := 1.5, 2.5; if a < b && b > a { printInt(1) }", "1")
+	o("a, b := 1.5, 1.5; if a <= b && a >= b { printInt(1) }", "1")
+	o("a:=0.1; if a == 0.1 && a != 0.2 { printInt(1) }", "1")
+	o("a:=-0.0; if a == 0.0 { printInt(1) }", "1")
 }
 
 func TestBareFunc_bad(t *testing.T) {
@@ -220,6 +239,9 @@ func TestBareFunc_bad(t *testing.T) {
 	o("a := 3%0")
 
 	o("const a = -1; printUint(a)")
+	o("a := 1.5 % 2.5") // no float mod
+	o("a := uint(1.5)") // only casts to int
+	o("a := 1.5; b:=a+int(3)")
 }
 
 func TestBareFunc_panic(t *testing.T) {
@@ -250,3 +272,19 @@ func TestBareFunc_panic(t *testing.T) {
 	o("d:=0; a:=-3%d")
 	o("var d [3]int; s:=d[:]; s=nil; printInt(s[1])")
 }
+
+func TestBareFunc_float(t *testing.T) {
+	o := func(input string) {
+		_, e := bareTestRun(t, input, 100000)
+		if !arch8.IsErr(e, arch8.ErrFloat) {
+			t.Log(input)
+			t.Log(e)
+			t.Error("should raise float exception")
+		}
+	}
+
+	o("z:=0.0; a:=1.0/z")
+	o("a:=3e38; a*=10")
+	o("a:=sqrt(-1.0)")
+	o("a:=int(3e9)")
+}
@@ -56,6 +56,26 @@ func declareBuiltin(b *builder, builtin *link8.Pkg) {
 		[]types.T{types.Uint, types.Uint, types.Uint},
 	))
 
+	pu := types.NewPointer(types.Uint)
+	o("AtomicCAS", "atomicCAS", types.NewFuncUnamed(
+		[]types.T{pu, types.Uint, types.Uint}, []types.T{types.Uint},
+	))
+	o("AtomicAdd", "atomicAdd", types.NewFuncUnamed(
+		[]types.T{pu, types.Uint}, []types.T{types.Uint},
+	))
+	o("LoadLinked", "loadLinked", types.NewFuncUnamed(
+		[]types.T{pu}, []types.T{types.Uint},
+	))
+	o("StoreCond", "storeCond", types.NewFuncUnamed(
+		[]types.T{pu, types.Uint}, []types.T{types.Bool},
+	))
+	o("Fence", "fence", types.VoidFunc)
+	o("Ipi", "ipi", types.NewVoidFunc(types.Uint))
+	o("Wfi", "wfi", types.VoidFunc)
+	o("Sqrt", "sqrt", types.NewFuncUnamed(
+		[]types.T{types.Float32}, []types.T{types.Float32},
+	))
+
 	// TODO: these are just hacks for context switch
 	oe := func(name string, as string, t *types.Func) {
 		sym := builtin.SymbolByName(name)
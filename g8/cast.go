@@ -22,6 +22,22 @@ func regSizeCastable(to, from types.T) bool {
 	return false
 }
 
+// floatCastOp returns the arith op that converts between an int and a
+// float.
+func floatCastOp(to, from types.T) (string, bool) {
+	toFloat := types.IsBasic(to, types.Float32)
+	fromFloat := types.IsBasic(from, types.Float32)
+	switch {
+	case toFloat && fromFloat:
+		return "", true
+	case toFloat && types.IsBasic(from, types.Int):
+		return "itof", true
+	case fromFloat && types.IsBasic(to, types.Int):
+		return "ftoi", true
+	}
+	return "", false
+}
+
 func buildCast(b *builder, expr *ast.CallExpr, t types.T) *ref {
 	pos := expr.Lparen.Pos
 
@@ -38,7 +54,7 @@ func buildCast(b *builder, expr *ast.CallExpr, t types.T) *ref {
 	srcType := args.Type()
 	ret := b.newTemp(t)
 	if c, ok := srcType.(*types.Const); ok {
-		if v, ok := types.NumConst(srcType); ok && types.IsInteger(t) {
+		if v, ok := types.NumConst(srcType); ok && isNumeric(t) {
 			return constCast(b, pos, v, t)
 		}
 		srcType = c.Type // using the underlying type
@@ -48,6 +64,10 @@ func buildCast(b *builder, expr *ast.CallExpr, t types.T) *ref {
 		b.b.Arith(ret.IR(), nil, "cast", args.IR())
 		return ret
 	}
+	if op, ok := floatCastOp(t, srcType); ok {
+		b.b.Arith(ret.IR(), nil, op, args.IR())
+		return ret
+	}
 	if regSizeCastable(t, srcType) {
 		b.b.Arith(ret.IR(), nil, "", args.IR())
 		return ret
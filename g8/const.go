@@ -1,6 +1,8 @@
 package g8
 
 import (
+	"math"
+
 	"e8vm.io/e8vm/g8/ir"
 	"e8vm.io/e8vm/g8/types"
 	"e8vm.io/e8vm/lex8"
@@ -16,15 +18,17 @@ func constNumIr(v int64, t types.T) ir.Ref {
 			return ir.Num(uint32(v))
 		case types.Int8, types.Uint8:
 			return ir.Byt(uint8(v))
+		case types.Float32:
+			return ir.Num(math.Float32bits(float32(v)))
 		}
 	}
-	panic("expect an integer type")
+	panic("expect an integer or float type")
 }
 
 func constCast(
 	b *builder, pos *lex8.Pos, v int64, to types.T,
 ) *ref {
-	if types.IsInteger(to) && types.InRange(v, to) {
+	if isNumeric(to) && types.InRange(v, to) {
 		return newRef(to, constNumIr(v, to))
 	}
 
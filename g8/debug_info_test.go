@@ -0,0 +1,61 @@
+package g8
+
+import (
+	"bytes"
+	"testing"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/e8"
+)
+
+func TestDebugInfo(t *testing.T) {
+	const input = `func f(a int) int {
+	if a > 3 {
+		panic()
+	}
+	return a
+}
+
+func main() {
+	f(1)
+	f(5)
+}`
+	bs, es, _ := CompileSingle("main.g", input, false)
+	if es != nil {
+		for _, e := range es {
+			t.Log(e)
+		}
+		t.Fatal("compile failed")
+	}
+
+	secs, err := e8.Read(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := e8.ImageLines(secs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines == nil {
+		t.Fatal("no line table")
+	}
+
+	m := arch8.NewMachine(0, 1)
+	if err := m.LoadImageBytes(bs); err != nil {
+		t.Fatal(err)
+	}
+	_, e := m.Run(100000)
+	if e == nil || e.Code != arch8.ErrPanic {
+		t.Fatalf("got %v, want panic", e)
+	}
+
+	// the panic function is in assembly, so looks up the caller
+	r := m.ExcepReport(e)
+	if pos := lines.Lookup(r.PC); pos != nil {
+		t.Errorf("got position %s in the panic function", pos)
+	}
+	pos := lines.Lookup(r.Regs[arch8.RET] - 4)
+	if pos == nil || pos.String() != "main.g:3:3" {
+		t.Errorf("got position %v for the panic call", pos)
+	}
+}
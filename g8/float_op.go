@@ -0,0 +1,52 @@
+package g8
+
+import (
+	"e8vm.io/e8vm/g8/types"
+	"e8vm.io/e8vm/lex8"
+)
+
+// isNumeric checks if a type is an integer or a float.
+func isNumeric(t types.T) bool {
+	return types.IsInteger(t) || types.IsBasic(t, types.Float32)
+}
+
+func binaryOpFloat(b *builder, opTok *lex8.Token, A, B *ref) *ref {
+	op := opTok.Lit
+	switch op {
+	case "+", "-", "*", "/":
+		ret := b.newTemp(types.Float32)
+		b.b.Arith(ret.IR(), A.IR(), "f"+op, B.IR())
+		return ret
+	case "==", "!=", ">", "<", ">=", "<=":
+		ret := b.newTemp(types.Bool)
+		b.b.Arith(ret.IR(), A.IR(), "f"+op, B.IR())
+		return ret
+	}
+
+	b.Errorf(opTok.Pos, "%q on floats", op)
+	return nil
+}
+
+func unaryOpFloat(b *builder, opTok *lex8.Token, B *ref) *ref {
+	op := opTok.Lit
+	switch op {
+	case "+":
+		return B
+	case "-":
+		ret := b.newTemp(types.Float32)
+		b.b.Arith(ret.IR(), nil, "f-", B.IR())
+		return ret
+	}
+
+	b.Errorf(opTok.Pos, "invalid operation: %q on %s", op, B)
+	return nil
+}
+
+func opAssignFloat(b *builder, opOp string, dest, src *ref) bool {
+	switch opOp {
+	case "+", "-", "*", "/":
+		b.b.Arith(dest.IR(), dest.IR(), "f"+opOp, src.IR())
+		return true
+	}
+	return false
+}
@@ -39,6 +39,7 @@ func buildForStmt(b *builder, stmt *ast.ForStmt) {
 		body.Jump(condBlock)
 
 		b.b = condBlock
+		b.b.SetPos(ast.ExprPos(stmt.Cond))
 		c := b.buildExpr(stmt.Cond)
 		if c == nil {
 			return
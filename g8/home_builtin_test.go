@@ -0,0 +1,43 @@
+package g8
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/asm8"
+	"e8vm.io/e8vm/build8"
+)
+
+// TestHomeBuiltin builds a program with the builtin package in home, so
+// that it provides all the builtin functions that the compiler requires.
+func TestHomeBuiltin(t *testing.T) {
+	src, err := ioutil.ReadFile("../home/src/asm/builtin/builtin.s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := build8.NewMemHome(Lang())
+	home.AddLang("asm", asm8.Lang())
+	home.NewPkg("main").AddFile("main.g", "main.g",
+		"func main() { printInt(-37); printChar('\\n') }",
+	)
+	home.NewPkg("asm/builtin").AddFile("", "builtin.s", string(src))
+
+	b := build8.NewBuilder(home)
+	if es := b.BuildAll(false); es != nil {
+		for _, e := range es {
+			t.Log(e)
+		}
+		t.Fatal("build with the builtin package in home failed")
+	}
+
+	_, out, e := arch8.RunImageOutput(home.Bin("main"), 100000)
+	if !arch8.IsHalt(e) {
+		t.Fatalf("did not halt gracefully: %v", e)
+	}
+	if got := strings.TrimSpace(out); got != "-37" {
+		t.Errorf("got output %q, want %q", got, "-37")
+	}
+}
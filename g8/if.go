@@ -5,6 +5,7 @@ import (
 )
 
 func buildIf(b *builder, cond ast.Expr, ifs ast.Stmt, elses *ast.ElseStmt) {
+	b.b.SetPos(ast.ExprPos(cond))
 	c := b.buildExpr(cond)
 	if c == nil {
 		return
@@ -69,6 +69,21 @@ func (_s) sllv(d, s1, s2 uint32) uint32 { return asm.reg(A.SLLV, d, s1, s2) }
 func (_s) srla(d, s1, s2 uint32) uint32 { return asm.reg(A.SRLA, d, s1, s2) }
 func (_s) srlv(d, s1, s2 uint32) uint32 { return asm.reg(A.SRLV, d, s1, s2) }
 
+func (_s) freg(op, d, s1, s2 uint32) uint32 {
+	return S.InstReg(op, d, s1, s2, 0, 1)
+}
+
+func (_s) fadd(d, s1, s2 uint32) uint32 { return asm.freg(A.FADD, d, s1, s2) }
+func (_s) fsub(d, s1, s2 uint32) uint32 { return asm.freg(A.FSUB, d, s1, s2) }
+func (_s) fmul(d, s1, s2 uint32) uint32 { return asm.freg(A.FMUL, d, s1, s2) }
+func (_s) fdiv(d, s1, s2 uint32) uint32 { return asm.freg(A.FDIV, d, s1, s2) }
+func (_s) feq(d, s1, s2 uint32) uint32  { return asm.freg(A.FEQ, d, s1, s2) }
+func (_s) flt(d, s1, s2 uint32) uint32  { return asm.freg(A.FLT, d, s1, s2) }
+func (_s) fle(d, s1, s2 uint32) uint32  { return asm.freg(A.FLE, d, s1, s2) }
+func (_s) ftoi(d, s uint32) uint32      { return asm.freg(A.FTOI, d, s, 0) }
+func (_s) fitof(d, s uint32) uint32     { return asm.freg(A.FITOF, d, s, 0) }
+func (_s) fneg(d, s uint32) uint32      { return asm.freg(A.FNEG, d, s, 0) }
+
 func (_s) srl(d, s1, v uint32) uint32 {
 	return S.InstReg(A.SRL, d, s1, 0, v, 0)
 }
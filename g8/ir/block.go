@@ -2,6 +2,8 @@ package ir
 
 import (
 	"fmt"
+
+	"e8vm.io/e8vm/lex8"
 )
 
 const (
@@ -27,6 +29,8 @@ type Block struct {
 
 	frameSize *int32
 
+	pos *lex8.Pos // source position of the instructions on code gen
+
 	jump *blockJump
 
 	next *Block // next in the linked list
@@ -49,6 +53,11 @@ func (b *Block) Commentf(s string, args ...interface{}) {
 	b.Comment(fmt.Sprintf(s, args...))
 }
 
+// SetPos sets the source position of the operations appended after.
+func (b *Block) SetPos(pos *lex8.Pos) {
+	b.addOp(&posOp{pos})
+}
+
 // Arith append an arithmetic operation to the basic block
 func (b *Block) Arith(dest Ref, x Ref, op string, y Ref) {
 	b.addOp(&arithOp{dest, x, op, y})
@@ -93,7 +102,7 @@ func (b *Block) JumpIf(cond Ref, dest *Block) {
 }
 
 func (b *Block) inst(i uint32) *inst {
-	ret := &inst{inst: i}
+	ret := &inst{inst: i, pos: b.pos}
 	b.insts = append(b.insts, ret)
 	return ret
 }
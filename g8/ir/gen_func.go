@@ -126,6 +126,8 @@ func genFunc(g *gener, f *Func) {
 		return
 	}
 
+	f.prologue.pos = f.pos
+	f.epilogue.pos = f.pos
 	if f.isMain {
 		makeMainPrologue(f)
 		makeMainEpilogue(f)
@@ -134,8 +136,11 @@ func genFunc(g *gener, f *Func) {
 		makeEpilogue(g, f)
 	}
 
+	pos := f.pos
 	for b := f.prologue.next; b != f.epilogue; b = b.next {
+		b.pos = pos // continues from the previous block
 		genBlock(g, b)
+		pos = b.pos
 	}
 
 	// TODO: check ranges
@@ -16,6 +16,14 @@ var basicOpFuncs = map[string]func(
 	"|":   asm.or,
 	"^":   asm.xor,
 	"nor": asm.nor,
+
+	"f+":  asm.fadd,
+	"f-":  asm.fsub,
+	"f*":  asm.fmul,
+	"f/":  asm.fdiv,
+	"f==": asm.feq,
+	"f<":  asm.flt,
+	"f<=": asm.fle,
 }
 
 func genArithOp(g *gener, b *Block, op *arithOp) {
@@ -71,6 +79,13 @@ func genArithOp(g *gener, b *Block, op *arithOp) {
 				b.inst(asm.srla(_4, _4, _1))
 			case "u>>":
 				b.inst(asm.srlv(_4, _4, _1))
+			case "f!=":
+				b.inst(asm.feq(_4, _4, _1))
+				b.inst(asm.xori(_4, _4, 1)) // flip
+			case "f>":
+				b.inst(asm.flt(_4, _1, _4))
+			case "f>=":
+				b.inst(asm.fle(_4, _1, _4))
 			default:
 				panic("unknown arith op: " + op.op)
 			}
@@ -117,6 +132,15 @@ func genArithOp(g *gener, b *Block, op *arithOp) {
 			b.inst(asm.nor(_4, _0, _4))
 		case "&": // fetches the address of the block
 			loadAddr(b, _4, op.b)
+		case "f-":
+			loadRef(b, _4, op.b)
+			b.inst(asm.fneg(_4, _4))
+		case "itof":
+			loadRef(b, _4, op.b)
+			b.inst(asm.fitof(_4, _4))
+		case "ftoi":
+			loadRef(b, _4, op.b)
+			b.inst(asm.ftoi(_4, _4))
 		case "<0":
 			loadRef(b, _4, op.b)
 			b.inst(asm.slt(_4, _4, _0))
@@ -138,6 +162,8 @@ func genOp(g *gener, b *Block, op op) {
 		genCallOp(g, b, op)
 	case *comment:
 		// do nothing
+	case *posOp:
+		b.pos = op.pos
 	default:
 		panic("unknown op type")
 	}
@@ -1,5 +1,9 @@
 package ir
 
+import (
+	"e8vm.io/e8vm/lex8"
+)
+
 type linkSym struct {
 	fill int
 	pkg  string // package path, empty string for the same package
@@ -9,4 +13,5 @@ type linkSym struct {
 type inst struct {
 	inst uint32
 	sym  *linkSym // generated by FuncSym or VarSym on code gen
+	pos  *lex8.Pos
 }
@@ -1,5 +1,9 @@
 package ir
 
+import (
+	"e8vm.io/e8vm/lex8"
+)
+
 type op interface{}
 
 type arithOp struct {
@@ -19,3 +23,8 @@ type callOp struct {
 type comment struct {
 	s string
 }
+
+// posOp sets the source position of the ops that follow.
+type posOp struct {
+	pos *lex8.Pos
+}
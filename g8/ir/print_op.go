@@ -11,6 +11,8 @@ func printOp(p io.Writer, op op) {
 	switch op := op.(type) {
 	case *comment:
 		fmt.Fprintf(p, "// %s\n", op.s)
+	case *posOp:
+		// not printed
 	case *arithOp:
 		if op.a == nil {
 			if op.op == "" {
@@ -6,6 +6,7 @@ import (
 
 func writeBlock(f *link8.Func, b *Block) {
 	for _, inst := range b.insts {
+		f.SetPos(inst.pos)
 		f.AddInst(inst.inst)
 		if inst.sym != nil {
 			s := inst.sym
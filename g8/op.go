@@ -99,8 +99,7 @@ func buildBinaryOpExpr(b *builder, expr *ast.OpExpr) *ref {
 		case types.Bool:
 			return binaryOpBool(b, opTok, A, B)
 		case types.Float32:
-			b.Errorf(opPos, "floating point operations not implemented")
-			return nil
+			return binaryOpFloat(b, opTok, A, B)
 		}
 	}
 
@@ -143,6 +142,8 @@ func buildUnaryOpExpr(b *builder, expr *ast.OpExpr) *ref {
 		return unaryOpInt(b, opTok, B)
 	} else if types.IsBasic(btyp, types.Bool) {
 		return unaryOpBool(b, opTok, B)
+	} else if types.IsBasic(btyp, types.Float32) {
+		return unaryOpFloat(b, opTok, B)
 	}
 
 	b.Errorf(opPos, "invalid unary operator %q", op)
@@ -29,6 +29,15 @@ func buildInt(b *builder, op *lex8.Token) *ref {
 	return newRef(types.NewNumber(ret), nil)
 }
 
+func buildFloat(b *builder, op *lex8.Token) *ref {
+	v, e := strconv.ParseFloat(op.Lit, 32)
+	if e != nil {
+		b.Errorf(op.Pos, "invalid float: %s", e)
+		return nil
+	}
+	return newRef(types.Float32, ir.Num(math.Float32bits(float32(v))))
+}
+
 func buildChar(b *builder, op *lex8.Token) *ref {
 	v, e := strconv.Unquote(op.Lit)
 	if e != nil {
@@ -135,6 +144,8 @@ func buildOperand(b *builder, op *ast.Operand) *ref {
 	switch op.Token.Type {
 	case parse.Int:
 		return buildInt(b, op.Token)
+	case parse.Float:
+		return buildFloat(b, op.Token)
 	case parse.Char:
 		return buildChar(b, op.Token)
 	case parse.String:
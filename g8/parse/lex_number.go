@@ -22,8 +22,6 @@ func isHexDigit(r rune) bool {
 }
 
 func lexNumber(x *lex8.Lexer) *lex8.Token {
-	// TODO: lex floating point as well
-
 	start := x.Rune()
 	if !isDigit(start) {
 		panic("not starting with a number")
@@ -38,10 +36,35 @@ func lexNumber(x *lex8.Lexer) *lex8.Token {
 		for isHexDigit(x.Rune()) {
 			x.Next()
 		}
-	} else {
+		return x.MakeToken(Int)
+	}
+
+	for isDigit(x.Rune()) {
+		x.Next()
+	}
+
+	isFloat := false
+	if x.Rune() == '.' {
+		isFloat = true
+		x.Next()
 		for isDigit(x.Rune()) {
 			x.Next()
 		}
 	}
+
+	if r := x.Rune(); r == 'e' || r == 'E' {
+		isFloat = true
+		x.Next()
+		if r := x.Rune(); r == '+' || r == '-' {
+			x.Next()
+		}
+		for isDigit(x.Rune()) {
+			x.Next()
+		}
+	}
+
+	if isFloat {
+		return x.MakeToken(Float)
+	}
 	return x.MakeToken(Int)
 }
@@ -127,6 +127,8 @@ func TestStmts_good(t *testing.T) {
 		"a++",
 		"a--",
 		"ret := (a.b & 0x1) > 0",
+		"a := 1.5",
+		"a := 2. * 1e3 / 1.5E-2",
 	} {
 		buf := strings.NewReader(s)
 		stmts, es := Stmts("test.g", buf)
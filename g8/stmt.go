@@ -5,6 +5,8 @@ import (
 )
 
 func buildStmt(b *builder, stmt ast.Stmt) {
+	b.b.SetPos(ast.StmtPos(stmt))
+
 	switch stmt := stmt.(type) {
 	case *ast.EmptyStmt:
 		// do nothing
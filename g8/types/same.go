@@ -5,7 +5,8 @@ import (
 	"math"
 )
 
-// InRange checks if a const is in range of an integer type.
+// InRange checks if a const is in range of an integer type, or can be
+// converted to a float.
 func InRange(v int64, t T) bool {
 	t, ok := t.(Basic)
 	if !ok {
@@ -20,6 +21,8 @@ func InRange(v int64, t T) bool {
 		return v >= math.MinInt8 && v <= math.MaxInt8
 	case Uint8:
 		return v >= 0 && v <= math.MaxUint8
+	case Float32:
+		return true
 	}
 	return false
 }
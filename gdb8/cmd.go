@@ -0,0 +1,221 @@
+package gdb8
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"e8vm.io/e8vm/arch8"
+)
+
+const (
+	okReply  = "OK"
+	errReply = "E01"
+)
+
+// maxWatchLen is the maximum number of bytes a watchpoint can cover.
+const maxWatchLen = 64
+
+func parseHex(s string) (uint32, error) {
+	ret, err := strconv.ParseUint(s, 16, 32)
+	return uint32(ret), err
+}
+
+// parseAddrLen parses an "addr,len" pair.
+func parseAddrLen(s string) (uint32, uint32, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("invalid address range %q", s)
+	}
+	addr, err := parseHex(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := parseHex(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return addr, n, nil
+}
+
+func wordHex(w uint32) string {
+	var buf [4]byte
+	arch8.Endian.PutUint32(buf[:], w)
+	return hex.EncodeToString(buf[:])
+}
+
+func hexWord(s string) (uint32, error) {
+	bs, err := hex.DecodeString(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(bs) != 4 {
+		return 0, fmt.Errorf("invalid register value %q", s)
+	}
+	return arch8.Endian.Uint32(bs), nil
+}
+
+func (s *Stub) readRegs() string {
+	ret := ""
+	for i := 0; i < arch8.Nreg; i++ {
+		ret += wordHex(s.m.Reg(s.core, i))
+	}
+	return ret
+}
+
+func (s *Stub) writeRegs(arg string) string {
+	if len(arg) != arch8.Nreg*8 {
+		return errReply
+	}
+	var regs [arch8.Nreg]uint32
+	for i := range regs {
+		w, err := hexWord(arg[i*8 : i*8+8])
+		if err != nil {
+			return errReply
+		}
+		regs[i] = w
+	}
+	for i, w := range regs {
+		s.m.SetReg(s.core, i, w)
+	}
+	return okReply
+}
+
+func (s *Stub) readReg(arg string) string {
+	reg, err := parseHex(arg)
+	if err != nil || reg >= arch8.Nreg {
+		return errReply
+	}
+	return wordHex(s.m.Reg(s.core, int(reg)))
+}
+
+func (s *Stub) writeReg(arg string) string {
+	fields := strings.Split(arg, "=")
+	if len(fields) != 2 {
+		return errReply
+	}
+	reg, err := parseHex(fields[0])
+	if err != nil || reg >= arch8.Nreg {
+		return errReply
+	}
+	w, err := hexWord(fields[1])
+	if err != nil {
+		return errReply
+	}
+	s.m.SetReg(s.core, int(reg), w)
+	return okReply
+}
+
+func (s *Stub) readMem(arg string) string {
+	addr, n, err := parseAddrLen(arg)
+	if err != nil {
+		return errReply
+	}
+
+	var bs []byte
+	for i := uint32(0); i < n; i++ {
+		b, err := s.m.ReadVirtByte(s.core, addr+i)
+		if err != nil {
+			if i == 0 {
+				return errReply
+			}
+			break // partial read
+		}
+		bs = append(bs, b)
+	}
+	return hex.EncodeToString(bs)
+}
+
+func (s *Stub) writeMem(arg string) string {
+	fields := strings.Split(arg, ":")
+	if len(fields) != 2 {
+		return errReply
+	}
+	addr, n, err := parseAddrLen(fields[0])
+	if err != nil {
+		return errReply
+	}
+	bs, err := hex.DecodeString(fields[1])
+	if err != nil || uint32(len(bs)) != n {
+		return errReply
+	}
+
+	for i, b := range bs {
+		pa, err := s.m.PhyAddr(s.core, addr+uint32(i))
+		if err != nil {
+			return errReply
+		}
+		if err := s.m.WriteByte(pa, b); err != nil {
+			return errReply
+		}
+	}
+	return okReply
+}
+
+// point inserts or removes a breakpoint or a write watchpoint.
+func (s *Stub) point(insert bool, arg string) string {
+	fields := strings.SplitN(arg, ",", 2)
+	if len(fields) != 2 {
+		return errReply
+	}
+	addr, n, err := parseAddrLen(fields[1])
+	if err != nil {
+		return errReply
+	}
+
+	switch fields[0] {
+	case "0", "1": // software and hardware breakpoints
+		if insert {
+			s.m.AddBreakpoint(addr)
+		} else {
+			s.m.RemoveBreakpoint(addr)
+		}
+	case "2": // write watchpoint
+		if n == 0 || n > maxWatchLen {
+			return errReply
+		}
+		for i := uint32(0); i < n; i++ {
+			if insert {
+				s.m.AddWatchpoint(addr+i, true)
+			} else {
+				s.m.RemoveWatchpoint(addr+i, true)
+			}
+		}
+	default:
+		return "" // read and access watchpoints are not supported
+	}
+	return okReply
+}
+
+// parseThread parses a thread id into a core index. Thread id 0 and -1
+// stand for any core, and keep the current core.
+func (s *Stub) parseThread(arg string) (int, bool) {
+	if arg == "0" || arg == "-1" {
+		return s.core, true
+	}
+	id, err := parseHex(arg)
+	if err != nil || id == 0 || int(id) > s.m.Ncore() {
+		return 0, false
+	}
+	return int(id) - 1, true
+}
+
+func (s *Stub) setThread(arg string) string {
+	if arg == "" {
+		return errReply
+	}
+	core, ok := s.parseThread(arg[1:])
+	if !ok {
+		return errReply
+	}
+	s.core = core
+	return okReply
+}
+
+func (s *Stub) threadAlive(arg string) string {
+	if _, ok := s.parseThread(arg); !ok {
+		return errReply
+	}
+	return okReply
+}
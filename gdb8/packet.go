@@ -0,0 +1,162 @@
+package gdb8
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+)
+
+// interruptByte is the byte sent by the client to interrupt a running
+// target. It is sent out of any packet.
+const interruptByte = 0x03
+
+// conn is a packet connection of the remote serial protocol.
+// Incoming bytes are read by a separate go routine, so that the
+// connection can be polled for interrupts while the machine is running.
+type conn struct {
+	w    io.Writer
+	in   chan byte
+	err  error
+	last []byte // last packet sent, for retransmission
+}
+
+func newConn(rw io.ReadWriter) *conn {
+	ret := &conn{
+		w:  rw,
+		in: make(chan byte, 4096),
+	}
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := rw.Read(buf)
+			for _, b := range buf[:n] {
+				ret.in <- b
+			}
+			if err != nil {
+				ret.err = err
+				close(ret.in)
+				return
+			}
+		}
+	}()
+
+	return ret
+}
+
+func checksum(bs []byte) byte {
+	var ret byte
+	for _, b := range bs {
+		ret += b
+	}
+	return ret
+}
+
+func (c *conn) readByte() (byte, error) {
+	b, ok := <-c.in
+	if !ok {
+		return 0, c.err
+	}
+	return b, nil
+}
+
+// interrupted checks if the client has sent an interrupt, without
+// blocking. Other bytes received out of a packet are dropped.
+func (c *conn) interrupted() bool {
+	for {
+		select {
+		case b, ok := <-c.in:
+			if !ok {
+				return true
+			}
+			if b == interruptByte {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+}
+
+// readPacket reads the next packet with a valid checksum. It returns a
+// nil packet when the client sends an interrupt.
+func (c *conn) readPacket() ([]byte, error) {
+	for {
+		b, err := c.readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch b {
+		case '$':
+		case '-':
+			if c.last != nil {
+				if _, err := c.w.Write(c.last); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		case interruptByte:
+			return nil, nil
+		default: // acks and noises
+			continue
+		}
+
+		buf := new(bytes.Buffer)
+		for {
+			b, err := c.readByte()
+			if err != nil {
+				return nil, err
+			}
+			if b == '#' {
+				break
+			}
+			buf.WriteByte(b)
+		}
+
+		var sum [2]byte
+		for i := range sum {
+			if sum[i], err = c.readByte(); err != nil {
+				return nil, err
+			}
+		}
+
+		var want byte
+		_, err = fmt.Sscanf(string(sum[:]), "%02x", &want)
+		if err != nil || want != checksum(buf.Bytes()) {
+			log.Printf("gdb8: bad checksum of packet %q", buf.Bytes())
+			if _, err := c.w.Write([]byte("-")); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if _, err := c.w.Write([]byte("+")); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+// writePacket sends a packet with the payload.
+func (c *conn) writePacket(s string) error {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('$')
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		switch b {
+		case '$', '#', '}', '*':
+			buf.WriteByte('}')
+			buf.WriteByte(b ^ 0x20)
+		default:
+			buf.WriteByte(b)
+		}
+	}
+	payload := buf.Bytes()[1:]
+	fmt.Fprintf(buf, "#%02x", checksum(payload))
+
+	c.last = buf.Bytes()
+	_, err := c.w.Write(c.last)
+	return err
+}
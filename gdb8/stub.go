@@ -0,0 +1,202 @@
+// Package gdb8 implements a GDB remote serial protocol stub that
+// drives an arch8 machine, so that existing debugger front-ends can
+// inspect and control the simulation.
+//
+// Each core of the machine is presented as a thread, where thread id
+// n+1 is core n. Memory accesses use the virtual address space of the
+// selected core. Registers are r0-r4, sp, ret and pc, in that order,
+// each as a 32-bit little endian word.
+package gdb8
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"e8vm.io/e8vm/arch8"
+)
+
+// Signals reported to the client.
+const (
+	sigInt  = 2
+	sigIll  = 4
+	sigTrap = 5
+	sigAbrt = 6
+	sigFpe  = 8
+	sigSegv = 11
+)
+
+// runSlice is the number of ticks to run between two interrupt polls
+// when the machine is continuing.
+const runSlice = 1000
+
+// Stub serves the remote serial protocol for a machine.
+type Stub struct {
+	m    *arch8.Machine
+	c    *conn
+	core int // the core selected for registers, memory and stepping
+
+	stop string // last stop reply
+	done bool
+}
+
+// NewStub creates a protocol stub for the machine.
+func NewStub(m *arch8.Machine) *Stub {
+	return &Stub{
+		m:    m,
+		stop: fmt.Sprintf("S%02x", sigTrap),
+	}
+}
+
+// Serve serves a debugging session on the connection until the client
+// detaches, kills the target, or the connection is closed.
+func (s *Stub) Serve(rw io.ReadWriter) error {
+	s.c = newConn(rw)
+	s.done = false
+
+	for !s.done {
+		p, err := s.c.readPacket()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var reply string
+		var noReply bool
+		if p == nil { // interrupt when the machine is already stopped
+			reply = s.stop
+		} else {
+			reply, noReply = s.handle(string(p))
+		}
+		if noReply {
+			continue
+		}
+		if err := s.c.writePacket(reply); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stopped records and returns the stop reply for an exception.
+func (s *Stub) stopped(e *arch8.CoreExcep) string {
+	if e == nil {
+		s.stop = fmt.Sprintf("T%02xthread:%x;", sigTrap, s.core+1)
+		return s.stop
+	}
+
+	s.core = e.Core
+	sig := sigTrap
+	extra := ""
+	switch e.Code {
+	case arch8.ErrHalt:
+		s.stop = "W00"
+		return s.stop
+	case arch8.ErrBreakpoint:
+	case arch8.ErrWatchpoint:
+		extra = fmt.Sprintf("watch:%x;", e.Arg)
+	case arch8.ErrInvalidInst:
+		sig = sigIll
+	case arch8.ErrPanic:
+		sig = sigAbrt
+	case arch8.ErrFloat:
+		sig = sigFpe
+	case arch8.ErrOutOfRange, arch8.ErrMisalign, arch8.ErrPageFault,
+		arch8.ErrPageReadonly, arch8.ErrPageNoExec:
+		sig = sigSegv
+	}
+
+	s.stop = fmt.Sprintf("T%02x%sthread:%x;", sig, extra, e.Core+1)
+	return s.stop
+}
+
+// cont runs the machine until it stops or the client interrupts.
+func (s *Stub) cont() string {
+	for {
+		_, e := s.m.Run(runSlice)
+		if e != nil {
+			return s.stopped(e)
+		}
+		if s.c.interrupted() {
+			s.stop = fmt.Sprintf("T%02xthread:%x;", sigInt, s.core+1)
+			return s.stop
+		}
+	}
+}
+
+// handle handles a packet. It returns the reply, and if the packet
+// expects no reply.
+func (s *Stub) handle(p string) (string, bool) {
+	if p == "" {
+		return "", false
+	}
+
+	cmd, arg := p[0], p[1:]
+	switch cmd {
+	case '?':
+		return s.stop, false
+	case 'g':
+		return s.readRegs(), false
+	case 'G':
+		return s.writeRegs(arg), false
+	case 'p':
+		return s.readReg(arg), false
+	case 'P':
+		return s.writeReg(arg), false
+	case 'm':
+		return s.readMem(arg), false
+	case 'M':
+		return s.writeMem(arg), false
+	case 'c':
+		if arg != "" {
+			return errReply, false
+		}
+		return s.cont(), false
+	case 's':
+		if arg != "" {
+			return errReply, false
+		}
+		return s.stopped(s.m.StepCore(s.core)), false
+	case 'Z', 'z':
+		return s.point(cmd == 'Z', arg), false
+	case 'H':
+		return s.setThread(arg), false
+	case 'T':
+		return s.threadAlive(arg), false
+	case 'q':
+		return s.query(arg), false
+	case 'D':
+		s.done = true
+		return okReply, false
+	case 'k':
+		s.done = true
+		return "", true
+	}
+
+	return "", false // unsupported
+}
+
+func (s *Stub) query(q string) string {
+	switch {
+	case q == "C":
+		return fmt.Sprintf("QC%x", s.core+1)
+	case q == "fThreadInfo":
+		ret := "m"
+		for i := 0; i < s.m.Ncore(); i++ {
+			if i > 0 {
+				ret += ","
+			}
+			ret += fmt.Sprintf("%x", i+1)
+		}
+		return ret
+	case q == "sThreadInfo":
+		return "l"
+	case q == "Attached":
+		return "1"
+	case strings.HasPrefix(q, "Supported"):
+		return "PacketSize=1000"
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package gdb8
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"e8vm.io/e8vm/arch8"
+)
+
+func TestStub(t *testing.T) {
+	m := arch8.NewMachine(arch8.PageSize*64, 1)
+	addi := uint32(arch8.ADDI<<24 | 1<<21 | 1<<18 | 1) // addi r1 r1 1
+	m.WriteWord(arch8.InitPC, addi)
+	m.WriteWord(arch8.InitPC+4, addi)
+	m.WriteWord(arch8.InitPC+8, arch8.HALT<<24)
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go NewStub(m).Serve(server)
+
+	r := bufio.NewReader(client)
+	req := func(p, want string) {
+		fmt.Fprintf(client, "$%s#%02x", p, checksum([]byte(p)))
+		ack, err := r.ReadByte()
+		if err != nil || ack != '+' {
+			t.Fatalf("%q: no ack, got %q, %v", p, ack, err)
+		}
+		got, err := r.ReadString('#')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Discard(2); err != nil {
+			t.Fatal(err)
+		}
+		got = strings.TrimSuffix(strings.TrimPrefix(got, "$"), "#")
+		if got != want {
+			t.Fatalf("%q: got %q, want %q", p, got, want)
+		}
+	}
+
+	req("qC", "QC1")
+	req("p7", "00800000")
+	req("m8000,4", "01002401")
+	req("Z0,8004,4", "OK")
+	req("c", "T05thread:1;")
+	req("p1", "01000000")
+	req("z0,8004,4", "OK")
+	req("s", "T05thread:1;")
+	req("p7", "08800000")
+	req("P1=2a000000", "OK")
+	req("g", "00000000"+"2a000000"+strings.Repeat("00000000", 3)+
+		"00200200"+"00000000"+"08800000")
+	req("c", "W00")
+	req("D", "OK")
+}
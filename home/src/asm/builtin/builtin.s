@@ -1,3 +1,5 @@
+const serialAddr = 0x2000 // the address of serial port
+
 // a char is sent in via r1
 func PrintChar {
 	// use r2 and r3
@@ -5,7 +7,7 @@ func PrintChar {
 	sw r2 sp
 	sw r3 sp 4
 
-	ori r2 r0 0x2000 // the address of serial port
+	ori r2 r0 serialAddr
 .wait
 	lbu r3 r2 1
 	bne r3 r0 .wait // wait for invalid
@@ -182,3 +184,60 @@ func Syscall {
     syscall
     mov pc ret
 }
+
+// AtomicCAS swaps in a new word if the word equals to the old one
+//    r1 - address of the word
+//    r2 - the old word
+//    r3 - the new word
+// returns the word before in r1
+func AtomicCAS {
+	cas r2 r1 r3
+	mov r1 r2
+	mov pc ret
+}
+
+// AtomicAdd adds to a word
+//    r1 - address of the word
+//    r2 - the delta
+// returns the word before in r1
+func AtomicAdd {
+	xadd r1 r1 r2
+	mov pc ret
+}
+
+// LoadLinked reads the word at r1 and reserves it
+func LoadLinked {
+	ll r1 r1
+	mov pc ret
+}
+
+// StoreCond writes r2 to the word at r1 if it is still reserved,
+// returns 1 in r1 if written
+func StoreCond {
+	sc r2 r1
+	mov r1 r2
+	mov pc ret
+}
+
+func Fence {
+	fence
+	mov pc ret
+}
+
+// Ipi interrupts the core of index r1
+func Ipi {
+	ipi r1
+	mov pc ret
+}
+
+// Wfi waits for an interrupt
+func Wfi {
+	wfi
+	mov pc ret
+}
+
+// Sqrt returns the square root of the float in r1
+func Sqrt {
+	fsqrt r1 r1
+	mov pc ret
+}
@@ -1,3 +1,5 @@
+const serialAddr = 0x2000 // the address of serial port
+
 // a char is sent in via r1
 func PrintChar {
 	// use r2 and r3
@@ -5,7 +7,7 @@ func PrintChar {
 	sw r2 sp
 	sw r3 sp 4
 
-	ori r2 r0 0x2000 // the address of serial port
+	ori r2 r0 serialAddr
 .wait
 	lbu r3 r2 1
 	bne r3 r0 .wait // wait for invalid
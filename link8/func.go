@@ -2,12 +2,15 @@ package link8
 
 import (
 	"math"
+
+	"e8vm.io/e8vm/lex8"
 )
 
 // Func is a relocatable code section
 type Func struct {
 	insts []uint32
 	links []*link
+	lines []*funcLine
 
 	addr uint32
 }
@@ -22,6 +25,39 @@ func (f *Func) AddInst(i uint32) {
 	f.insts = append(f.insts, i)
 }
 
+// funcLine is the source position of the instructions starting at index.
+type funcLine struct {
+	index int
+	pos   *lex8.Pos
+}
+
+// SetPos sets the source position of the instructions appended after.
+// A nil position marks the instructions with no source position.
+func (f *Func) SetPos(pos *lex8.Pos) {
+	n := len(f.lines)
+	if n > 0 {
+		last := f.lines[n-1]
+		if samePos(last.pos, pos) {
+			return
+		}
+		if last.index == len(f.insts) {
+			f.lines = f.lines[:n-1] // no instruction at last position
+			f.SetPos(pos)
+			return
+		}
+	} else if pos == nil {
+		return
+	}
+	f.lines = append(f.lines, &funcLine{index: len(f.insts), pos: pos})
+}
+
+func samePos(p1, p2 *lex8.Pos) bool {
+	if p1 == nil || p2 == nil {
+		return p1 == p2
+	}
+	return *p1 == *p2
+}
+
 // TooLarge checks if the function size is larger than 4GB.
 func (f *Func) TooLarge() bool {
 	return len(f.insts)*4 >= math.MaxInt32
@@ -2,6 +2,8 @@ package link8
 
 import (
 	"fmt"
+
+	"e8vm.io/e8vm/arch8"
 )
 
 func layout(used []pkgSym, initPC uint32) (
@@ -37,6 +39,16 @@ func layout(used []pkgSym, initPC uint32) (
 
 	const dataMax uint32 = 0xffffffff
 
+	// start the data on a new page, so that the code pages can be
+	// mapped readonly and the data pages not executable.
+	if len(funcs) > 0 && pt%arch8.PageSize != 0 {
+		pad := arch8.PageSize - pt%arch8.PageSize
+		if pad > dataMax-pt {
+			return nil, nil, nil, fmt.Errorf("binary too large")
+		}
+		pt += pad
+	}
+
 	putVar := func(v *Var) error {
 		if v.align > 1 && pt%v.align != 0 {
 			v.prePad = v.align - pt%v.align
@@ -0,0 +1,37 @@
+package link8
+
+import (
+	"e8vm.io/e8vm/e8"
+)
+
+// imageLines lists the line entries of the functions linked in the image.
+// The code after a function with source positions is ended with an entry
+// that has no file.
+func imageLines(funcs []pkgSym) []*e8.LineEntry {
+	var ret []*e8.LineEntry
+	add := func(e *e8.LineEntry) {
+		n := len(ret)
+		if n > 0 && ret[n-1].Addr == e.Addr {
+			ret = ret[:n-1] // overwrites an empty range
+		}
+		ret = append(ret, e)
+	}
+
+	for _, ps := range funcs {
+		f := ps.Func()
+		if len(f.lines) == 0 {
+			continue
+		}
+		for _, line := range f.lines {
+			e := &e8.LineEntry{Addr: f.addr + uint32(line.index)*4}
+			if line.pos != nil {
+				e.File = line.pos.File
+				e.Line = uint32(line.pos.Line)
+				e.Col = uint32(line.pos.Col)
+			}
+			add(e)
+		}
+		add(&e8.LineEntry{Addr: f.addr + f.Size()})
+	}
+	return ret
+}
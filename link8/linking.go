@@ -14,6 +14,21 @@ type Job struct {
 	Pkg      *Pkg
 	StartSym string
 	InitPC   uint32
+
+	// Map receives the linker map when not nil, which lists the
+	// address and size of every symbol linked in the image.
+	Map io.Writer
+
+	// NoSymbols skips the Symbols section, which has the same symbols
+	// as the linker map.
+	NoSymbols bool
+
+	// NoDebugInfo skips the DebugInfo section, which maps the code to
+	// the source positions.
+	NoDebugInfo bool
+
+	// Compress compresses the code and data sections.
+	Compress bool
 }
 
 // NewJob creates a new linking job which init pc is the default one.
@@ -71,6 +86,13 @@ func (j *Job) Link(out io.Writer) error {
 		return e
 	}
 
+	syms := imageSymbols(funcs, vars, zeros)
+	if j.Map != nil {
+		if err := writeMap(j.Map, syms); err != nil {
+			return err
+		}
+	}
+
 	var secs []*e8.Section
 	if len(funcs) > 0 {
 		buf := new(bytes.Buffer)
@@ -86,6 +108,7 @@ func (j *Job) Link(out io.Writer) error {
 			secs = append(secs, &e8.Section{
 				Header: &e8.Header{
 					Type: e8.Code,
+					Flag: e8.FlagReadonly | e8.FlagExec,
 					Addr: j.InitPC,
 				},
 				Bytes: buf.Bytes(),
@@ -127,7 +150,36 @@ func (j *Job) Link(out io.Writer) error {
 		})
 	}
 
-	return e8.Write(out, secs)
+	if !j.NoSymbols && len(syms) > 0 {
+		secs = append(secs, &e8.Section{
+			Header: &e8.Header{Type: e8.Symbols},
+			Bytes:  e8.EncodeSymbols(syms),
+		})
+	}
+
+	if !j.NoDebugInfo {
+		if lines := imageLines(funcs); len(lines) > 0 {
+			secs = append(secs, &e8.Section{
+				Header: &e8.Header{Type: e8.DebugInfo},
+				Bytes:  e8.EncodeLines(lines),
+			})
+		}
+	}
+
+	if j.Compress {
+		for _, s := range secs {
+			if s.Type == e8.Code || s.Type == e8.Data {
+				s.Flag |= e8.FlagCompressed
+			}
+		}
+	}
+
+	img := &e8.Image{Sections: secs}
+	if start := j.Pkg.Func(j.StartSym); start.Size() > 0 {
+		img.Entry = start.addr
+		img.HasEntry = true
+	}
+	return e8.WriteImage(out, img)
 }
 
 // LinkBareFunc produces a image of a single function that has no links.
@@ -147,11 +199,17 @@ func LinkBareFunc(f *Func) ([]byte, error) {
 	sec := &e8.Section{
 		Header: &e8.Header{
 			Type: e8.Code,
+			Flag: e8.FlagReadonly | e8.FlagExec,
 			Addr: arch8.InitPC,
 		},
 		Bytes: buf.Bytes(),
 	}
-	if err := e8.Write(image, []*e8.Section{sec}); err != nil {
+	img := &e8.Image{Sections: []*e8.Section{sec}}
+	if f.Size() > 0 {
+		img.Entry = arch8.InitPC
+		img.HasEntry = true
+	}
+	if err := e8.WriteImage(image, img); err != nil {
 		return nil, err
 	}
 
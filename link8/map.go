@@ -0,0 +1,55 @@
+package link8
+
+import (
+	"fmt"
+	"io"
+
+	"e8vm.io/e8vm/e8"
+)
+
+// imageSymbols lists the symbols linked in the image, named as
+// "pkg.name". The functions come first, and then the variables, both
+// ordered by address.
+func imageSymbols(funcs, vars, zeros []pkgSym) []*e8.Symbol {
+	var ret []*e8.Symbol
+	for _, ps := range funcs {
+		f := ps.Func()
+		ret = append(ret, &e8.Symbol{
+			Type: e8.SymFunc,
+			Name: ps.pkg.path + "." + ps.sym,
+			Addr: f.addr,
+			Size: f.Size(),
+		})
+	}
+
+	for _, lst := range [][]pkgSym{vars, zeros} {
+		for _, ps := range lst {
+			v := ps.Var()
+			ret = append(ret, &e8.Symbol{
+				Type: e8.SymVar,
+				Name: ps.pkg.path + "." + ps.sym,
+				Addr: v.addr,
+				Size: v.Size(),
+			})
+		}
+	}
+	return ret
+}
+
+// writeMap writes the linker map, one symbol on each line, in the form
+// of "addr size type pkg.name".
+func writeMap(w io.Writer, syms []*e8.Symbol) error {
+	for _, s := range syms {
+		typ := "func"
+		if s.Type == e8.SymVar {
+			typ = "var"
+		}
+		_, err := fmt.Fprintf(w, "%08x %d %s %s\n",
+			s.Addr, s.Size, typ, s.Name,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package prof8
+
+import (
+	"e8vm.io/e8vm/arch8"
+)
+
+// Frame is a frame in a backtrace.
+type Frame struct {
+	PC  uint32
+	Sym *Symbol // nil if unknown
+}
+
+// maxFrames limits the depth of a backtrace.
+const maxFrames = 64
+
+// frameSize returns the frame size of a function from its prologue,
+// which saves the return address at sp-4 and then pushes the frame by
+// moving the sp down. It returns false if the function does not start
+// with such a prologue.
+func frameSize(m *arch8.Machine, core int, sym *Symbol) (uint32, bool) {
+	saveRet := arch8.SW<<24 | arch8.RET<<21 | arch8.SP<<18 | 0xfffc
+	in, err := m.ReadVirtWord(core, sym.Addr)
+	if err != nil || in != uint32(saveRet) {
+		return 0, false
+	}
+
+	pushFrame := arch8.ADDI<<24 | arch8.SP<<21 | arch8.SP<<18
+	in, err = m.ReadVirtWord(core, sym.Addr+4)
+	if err != nil || in&0xffff0000 != uint32(pushFrame) {
+		return 0, false
+	}
+	return -uint32(int16(in)), true
+}
+
+// Backtrace walks the stack of a core with the function symbols, and
+// returns the frames from the current PC up to the callers. The frame
+// of a caller has the PC of the call. The walk is best effort: it
+// assumes the prologues and epilogues that the g8 compiler generates,
+// and stops at the first function that it cannot walk through.
+func Backtrace(m *arch8.Machine, core int, t *SymTable) []*Frame {
+	pc := m.Reg(core, arch8.PC)
+	sp := m.Reg(core, arch8.SP)
+	ret := m.Reg(core, arch8.RET)
+
+	var frames []*Frame
+	for len(frames) < maxFrames {
+		sym := t.Lookup(pc)
+		frames = append(frames, &Frame{PC: pc, Sym: sym})
+		if sym == nil {
+			break
+		}
+		size, ok := frameSize(m, core, sym)
+		if !ok {
+			break
+		}
+
+		var retAddr uint32
+		switch {
+		case pc == sym.Addr: // return address not saved yet
+			retAddr = ret
+		case pc == sym.Addr+4 || isReturn(m, core, pc):
+			// frame not pushed yet, or popped already
+			w, err := m.ReadVirtWord(core, sp-4)
+			if err != nil {
+				return frames
+			}
+			retAddr = w
+		default:
+			w, err := m.ReadVirtWord(core, sp+size-4)
+			if err != nil {
+				return frames
+			}
+			retAddr = w
+			sp += size
+		}
+
+		if retAddr == 0 || retAddr%4 != 0 {
+			break
+		}
+		pc = retAddr - 4
+	}
+	return frames
+}
+
+// isReturn checks if the instruction at pc loads the return address at
+// sp-4 into the pc, which is the end of an epilogue.
+func isReturn(m *arch8.Machine, core int, pc uint32) bool {
+	loadRet := arch8.LW<<24 | arch8.PC<<21 | arch8.SP<<18 | 0xfffc
+	in, err := m.ReadVirtWord(core, pc)
+	return err == nil && in == uint32(loadRet)
+}
@@ -0,0 +1,77 @@
+package prof8
+
+import (
+	"testing"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/asm8"
+)
+
+func TestBacktrace(t *testing.T) {
+	const (
+		sp  = arch8.SP
+		ret = arch8.RET
+		pc  = arch8.PC
+	)
+	imm := func(op, d, s uint32, im int32) uint32 {
+		return asm8.InstImm(op, d, s, uint32(im))
+	}
+	code := []uint32{
+		// main, with no return address saved
+		imm(arch8.ADDI, sp, sp, -8),
+		asm8.InstJmp(arch8.JAL, 1),
+		asm8.InstReg(arch8.PANIC, 0, 0, 0, 0, 0),
+
+		// f
+		imm(arch8.SW, ret, sp, -4),
+		imm(arch8.ADDI, sp, sp, -16),
+		asm8.InstJmp(arch8.JAL, 2),
+		imm(arch8.ADDI, sp, sp, 16),
+		imm(arch8.LW, pc, sp, -4),
+
+		// g
+		imm(arch8.SW, ret, sp, -4),
+		imm(arch8.ADDI, sp, sp, -8),
+		imm(arch8.ADDI, sp, sp, 8),
+		imm(arch8.LW, pc, sp, -4),
+	}
+	syms := NewSymTable([]*Symbol{
+		{"main", 0x8000, 12},
+		{"f", 0x800c, 20},
+		{"g", 0x8020, 16},
+	})
+
+	o := func(brk uint32, want ...uint32) {
+		m := arch8.NewMachine(arch8.PageSize*64, 1)
+		for i, in := range code {
+			m.WriteWord(arch8.InitPC+uint32(i)*4, in)
+		}
+		if brk != 0 {
+			m.AddBreakpoint(brk)
+		}
+		if _, e := m.Run(100); e == nil {
+			t.Fatal("program did not stop")
+		}
+
+		frames := Backtrace(m, 0, syms)
+		if len(frames) != len(want) {
+			t.Fatalf("break %x: got %d frames, want %d",
+				brk, len(frames), len(want),
+			)
+		}
+		for i, f := range frames {
+			if f.PC != want[i] {
+				t.Errorf("break %x: frame %d got pc %x, want %x",
+					brk, i, f.PC, want[i],
+				)
+			}
+		}
+	}
+
+	o(0, 0x8008)                      // panics in main
+	o(0x8020, 0x8020, 0x8014, 0x8004) // entering g
+	o(0x8024, 0x8024, 0x8014, 0x8004) // return address saved
+	o(0x8028, 0x8028, 0x8014, 0x8004) // frame pushed
+	o(0x802c, 0x802c, 0x8014, 0x8004) // frame popped
+	o(0x801c, 0x801c, 0x8004)         // returning from f
+}
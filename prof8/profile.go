@@ -0,0 +1,185 @@
+// Package prof8 profiles the guest programs running on an arch8
+// machine by sampling the program counters, and writes the profile
+// symbolized with the linker map.
+package prof8
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/e8"
+)
+
+type sampleKey struct {
+	pc   uint32
+	ring byte
+}
+
+// stack is a sampled call chain, with the leaf first.
+type stack struct {
+	pcs   []uint32
+	ring  byte
+	count int
+}
+
+// Profile collects the PC samples of a machine. It implements
+// arch8.Sampler.
+type Profile struct {
+	counts map[sampleKey]int
+	stacks map[string]*stack
+	total  int
+
+	m    *arch8.Machine // for walking the call chains
+	syms *SymTable
+}
+
+// NewProfile creates an empty profile that records only the sampled
+// PCs.
+func NewProfile() *Profile {
+	return &Profile{
+		counts: make(map[sampleKey]int),
+		stacks: make(map[string]*stack),
+	}
+}
+
+// NewStackProfile creates an empty profile that also records the call
+// chain of each sample, by walking the stack of the sampled core in m
+// with the function symbols.
+func NewStackProfile(m *arch8.Machine, t *SymTable) *Profile {
+	p := NewProfile()
+	p.m = m
+	p.syms = t
+	return p
+}
+
+// Sample records a PC sample.
+func (p *Profile) Sample(core int, pc uint32, ring byte) {
+	p.counts[sampleKey{pc, ring}]++
+	p.total++
+
+	pcs := []uint32{pc}
+	if p.m != nil {
+		pcs = pcs[:0]
+		for _, f := range Backtrace(p.m, core, p.syms) {
+			pcs = append(pcs, f.PC)
+		}
+	}
+
+	k := fmt.Sprint(ring, pcs)
+	s := p.stacks[k]
+	if s == nil {
+		s = &stack{pcs: pcs, ring: ring}
+		p.stacks[k] = s
+	}
+	s.count++
+}
+
+// Total returns the total number of samples.
+func (p *Profile) Total() int { return p.total }
+
+func symName(t *SymTable, pc uint32) string {
+	if sym := t.Lookup(pc); sym != nil {
+		return sym.Name
+	}
+	return fmt.Sprintf("0x%08x", pc)
+}
+
+type entry struct {
+	name  string
+	count int
+}
+
+// sorted aggregates the counts by name, in descending order of counts.
+func sorted(counts map[string]int) []*entry {
+	var ret []*entry
+	for name, n := range counts {
+		ret = append(ret, &entry{name, n})
+	}
+	sort.Sort(byCount(ret))
+	return ret
+}
+
+type byCount []*entry
+
+func (l byCount) Len() int { return len(l) }
+func (l byCount) Less(i, j int) bool {
+	if l[i].count != l[j].count {
+		return l[i].count > l[j].count
+	}
+	return l[i].name < l[j].name
+}
+func (l byCount) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+
+// WriteFolded writes the profile in the folded stack format that flame
+// graph tools take. Each line is a stack of frames separated by ';',
+// followed by the number of samples. The first frame is "kernel" or
+// "user" by the ring level, and the rest are the functions on the call
+// chain, from the outermost caller to the sampled function.
+func (p *Profile) WriteFolded(w io.Writer, t *SymTable) error {
+	counts := make(map[string]int)
+	for _, s := range p.stacks {
+		names := []string{"kernel"}
+		if s.ring > 0 {
+			names[0] = "user"
+		}
+		for i := len(s.pcs) - 1; i >= 0; i-- {
+			names = append(names, symName(t, s.pcs[i]))
+		}
+		counts[strings.Join(names, ";")] += s.count
+	}
+
+	for _, e := range sorted(counts) {
+		if _, err := fmt.Fprintf(w, "%s %d\n", e.name, e.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFlat writes a flat profile, listing the number of samples and the
+// percentage of each function.
+func (p *Profile) WriteFlat(w io.Writer, t *SymTable) error {
+	counts := make(map[string]int)
+	for k, n := range p.counts {
+		counts[symName(t, k.pc)] += n
+	}
+
+	for _, e := range sorted(counts) {
+		percent := float64(e.count) * 100 / float64(p.total)
+		_, err := fmt.Fprintf(w, "%8d %6.2f%%  %s\n",
+			e.count, percent, e.name,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteLines writes a flat profile of the source lines, listing the
+// number of samples and the percentage of each line. Samples with no
+// source position are counted as "unknown".
+func (p *Profile) WriteLines(w io.Writer, t *e8.LineTable) error {
+	counts := make(map[string]int)
+	for k, n := range p.counts {
+		name := "unknown"
+		if pos := t.Lookup(k.pc); pos != nil {
+			name = fmt.Sprintf("%s:%d", pos.File, pos.Line)
+		}
+		counts[name] += n
+	}
+
+	for _, e := range sorted(counts) {
+		percent := float64(e.count) * 100 / float64(p.total)
+		_, err := fmt.Fprintf(w, "%8d %6.2f%%  %s\n",
+			e.count, percent, e.name,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
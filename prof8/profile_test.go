@@ -0,0 +1,146 @@
+package prof8
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"e8vm.io/e8vm/arch8"
+	"e8vm.io/e8vm/asm8"
+	"e8vm.io/e8vm/e8"
+)
+
+func TestProfile(t *testing.T) {
+	const m = `00008000 8 func main.main
+00008008 12 func main.f
+00008014 4 var main.v
+`
+	syms, err := ReadMap(strings.NewReader(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		pc   uint32
+		name string
+	}{
+		{0x8000, "main.main"},
+		{0x8004, "main.main"},
+		{0x8010, "main.f"},
+		{0x8014, ""},
+		{0x7ffc, ""},
+	} {
+		sym := syms.Lookup(test.pc)
+		name := ""
+		if sym != nil {
+			name = sym.Name
+		}
+		if name != test.name {
+			t.Errorf("lookup %08x: got %q, want %q", test.pc, name, test.name)
+		}
+	}
+
+	p := NewProfile()
+	p.Sample(0, 0x8000, 0)
+	p.Sample(0, 0x8008, 1)
+	p.Sample(0, 0x800c, 1)
+	p.Sample(0, 0x9000, 0)
+
+	buf := new(bytes.Buffer)
+	if err := p.WriteFolded(buf, syms); err != nil {
+		t.Fatal(err)
+	}
+	want := "user;main.f 2\nkernel;0x00009000 1\nkernel;main.main 1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got folded profile:\n%s", got)
+	}
+
+	buf.Reset()
+	if err := p.WriteFlat(buf, syms); err != nil {
+		t.Fatal(err)
+	}
+	want = "       2  50.00%  main.f\n" +
+		"       1  25.00%  0x00009000\n" +
+		"       1  25.00%  main.main\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got flat profile:\n%s", got)
+	}
+
+	lines := e8.NewLineTable([]*e8.LineEntry{
+		{Addr: 0x8008, File: "a.g", Line: 3, Col: 2},
+		{Addr: 0x8000, File: "a.g", Line: 1, Col: 1},
+		{Addr: 0x8014},
+	})
+	buf.Reset()
+	if err := p.WriteLines(buf, lines); err != nil {
+		t.Fatal(err)
+	}
+	want = "       2  50.00%  a.g:3\n" +
+		"       1  25.00%  a.g:1\n" +
+		"       1  25.00%  unknown\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got line profile:\n%s", got)
+	}
+}
+
+func TestStackProfile(t *testing.T) {
+	const (
+		sp  = arch8.SP
+		ret = arch8.RET
+		pc  = arch8.PC
+	)
+	imm := func(op, d, s uint32, im int32) uint32 {
+		return asm8.InstImm(op, d, s, uint32(im))
+	}
+	callee := func(call ...uint32) []uint32 {
+		ret := []uint32{
+			imm(arch8.SW, ret, sp, -4),
+			imm(arch8.ADDI, sp, sp, -8),
+		}
+		ret = append(ret, call...)
+		return append(ret,
+			imm(arch8.ADDI, sp, sp, 8),
+			imm(arch8.LW, pc, sp, -4),
+		)
+	}
+
+	var code []uint32
+	code = append(code, // main, calls f and then g
+		imm(arch8.ADDI, sp, sp, -8),
+		asm8.InstJmp(arch8.JAL, 2),
+		asm8.InstJmp(arch8.JAL, 6),
+		arch8.HALT<<24,
+	)
+	code = append(code, callee(asm8.InstJmp(arch8.JAL, 7))...) // f
+	code = append(code, callee(asm8.InstJmp(arch8.JAL, 2))...) // g
+	code = append(code, callee()...)                           // h
+	syms := NewSymTable([]*Symbol{
+		{"main", 0x8000, 16},
+		{"f", 0x8010, 20},
+		{"g", 0x8024, 20},
+		{"h", 0x8038, 16},
+	})
+
+	m := arch8.NewMachine(arch8.PageSize*64, 1)
+	for i, in := range code {
+		m.WriteWord(arch8.InitPC+uint32(i)*4, in)
+	}
+	p := NewStackProfile(m, syms)
+	m.SetSampler(p, 1)
+	if _, e := m.Run(100); !arch8.IsHalt(e) {
+		t.Fatalf("did not halt gracefully: %v", e)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := p.WriteFolded(buf, syms); err != nil {
+		t.Fatal(err)
+	}
+	want := "kernel;main;f 5\n" +
+		"kernel;main;g 5\n" +
+		"kernel;main 4\n" +
+		"kernel;main;f;h 4\n" +
+		"kernel;main;g;h 4\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got folded profile:\n%s", got)
+	}
+}
@@ -0,0 +1,113 @@
+package prof8
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"e8vm.io/e8vm/e8"
+)
+
+// Symbol is a function symbol in an image.
+type Symbol struct {
+	Name string
+	Addr uint32
+	Size uint32
+}
+
+// SymTable maps addresses to function symbols.
+type SymTable struct {
+	syms []*Symbol // sorted by address
+}
+
+type bySymAddr []*Symbol
+
+func (l bySymAddr) Len() int           { return len(l) }
+func (l bySymAddr) Less(i, j int) bool { return l[i].Addr < l[j].Addr }
+func (l bySymAddr) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// NewSymTable creates a symbol table from a list of function symbols.
+func NewSymTable(syms []*Symbol) *SymTable {
+	ret := &SymTable{syms: append([]*Symbol(nil), syms...)}
+	sort.Sort(bySymAddr(ret.syms))
+	return ret
+}
+
+// ReadMap reads the function symbols from a linker map written by
+// link8.
+func ReadMap(r io.Reader) (*SymTable, error) {
+	var syms []*Symbol
+	s := bufio.NewScanner(r)
+	for lineno := 1; s.Scan(); lineno++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+
+		var sym Symbol
+		var typ string
+		_, err := fmt.Sscanf(line, "%x %d %s %s",
+			&sym.Addr, &sym.Size, &typ, &sym.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineno, err)
+		}
+		if typ == "func" {
+			syms = append(syms, &sym)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewSymTable(syms), nil
+}
+
+// OpenMap reads the function symbols from a linker map file.
+func OpenMap(path string) (*SymTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadMap(f)
+}
+
+// ImageSymbols reads the function symbols from the Symbols section of an
+// image, or returns nil if the image has no symbols.
+func ImageSymbols(secs []*e8.Section) (*SymTable, error) {
+	syms, err := e8.FindSymbols(secs)
+	if err != nil || syms == nil {
+		return nil, err
+	}
+
+	var funcs []*Symbol
+	for _, s := range syms {
+		if s.Type == e8.SymFunc {
+			funcs = append(funcs, &Symbol{s.Name, s.Addr, s.Size})
+		}
+	}
+	return NewSymTable(funcs), nil
+}
+
+// Lookup returns the function symbol that covers the address, or nil if
+// there is none.
+func (t *SymTable) Lookup(addr uint32) *Symbol {
+	if t == nil {
+		return nil
+	}
+
+	n := len(t.syms)
+	i := sort.Search(n, func(i int) bool { return t.syms[i].Addr > addr })
+	if i == 0 {
+		return nil
+	}
+	sym := t.syms[i-1]
+	if addr-sym.Addr >= sym.Size {
+		return nil
+	}
+	return sym
+}